@@ -0,0 +1,284 @@
+package retry
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned instead of calling the retried function while the circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState represents the state of a CircuitBreaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets every call through and counts failures
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects every call until the cool-down elapses
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe calls through to decide whether to close again
+	CircuitHalfOpen
+)
+
+// String returns the name of the state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker stops calls to a failing dependency for a cool-down period.
+//
+// The breaker is closed by default, it trips open when the configured trip policy
+// (consecutive failures and/or failure ratio) is reached, stays open for the cool-down
+// period and then lets a limited number of probe calls through in the half-open state.
+// The breaker closes again when all probes succeed and re-opens on the first failed probe.
+//
+// A call which panics counts as failed, a call which fails after its context was cancelled
+// (by the caller or by Hedging cancelling the slower copies) is not counted at all.
+//
+// A CircuitBreaker is safe for concurrent use and is meant to be shared between
+// all retriers calling the same dependency:
+//
+//	cb := retry.NewCircuitBreaker(
+//		retry.BreakerConsecutiveFailures(5),
+//		retry.BreakerCoolDown(10*time.Second),
+//	)
+//
+//	retrier := retry.New(retry.WithCircuitBreaker(cb))
+type CircuitBreaker struct {
+	consecutiveFailures uint
+	failureRatio        float64
+	minRequests         uint
+	window              time.Duration
+	coolDown            time.Duration
+	halfOpenProbes      uint
+	now                 func() time.Time
+
+	mu          sync.Mutex
+	state       CircuitState
+	generation  uint64
+	changedAt   time.Time
+	windowAt    time.Time
+	requests    uint
+	failures    uint
+	consecutive uint
+	inFlight    uint
+	probeOK     uint
+}
+
+// CircuitBreakerOption represents an option for CircuitBreaker.
+type CircuitBreakerOption func(*CircuitBreaker)
+
+// NewCircuitBreaker creates a new closed CircuitBreaker with the given options.
+func NewCircuitBreaker(opts ...CircuitBreakerOption) *CircuitBreaker {
+	cb := &CircuitBreaker{
+		consecutiveFailures: 5,
+		coolDown:            30 * time.Second,
+		halfOpenProbes:      1,
+		now:                 time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(cb)
+	}
+
+	if cb.halfOpenProbes == 0 {
+		cb.halfOpenProbes = 1
+	}
+	cb.changedAt = cb.now()
+	cb.windowAt = cb.changedAt
+
+	return cb
+}
+
+// BreakerConsecutiveFailures trips the breaker after `n` consecutive failed calls.
+// Setting to 0 disables this trip policy.
+// default is 5
+func BreakerConsecutiveFailures(n uint) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.consecutiveFailures = n
+	}
+}
+
+// BreakerFailureRatio trips the breaker when the ratio of failed calls reaches `ratio`
+// once at least `minRequests` calls were made in the closed state.
+// does not apply by default
+func BreakerFailureRatio(ratio float64, minRequests uint) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.failureRatio = ratio
+		cb.minRequests = minRequests
+	}
+}
+
+// BreakerWindow clears the counters of the failure ratio of the closed breaker every `window`
+// so old results don't affect the failure ratio forever.
+// Consecutive failures and calls in flight are still counted across windows.
+// default is 0 (counters are cleared only on state change)
+func BreakerWindow(window time.Duration) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.window = window
+	}
+}
+
+// BreakerCoolDown sets how long the breaker stays open before letting probes through
+// default is 30s
+func BreakerCoolDown(coolDown time.Duration) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.coolDown = coolDown
+	}
+}
+
+// BreakerHalfOpenProbes sets how many concurrent probe calls are allowed in the half-open
+// state; the breaker closes once that many probes succeeded
+// default is 1
+func BreakerHalfOpenProbes(n uint) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.halfOpenProbes = n
+	}
+}
+
+// BreakerClock provides a way to swap out the source of the current time, useful for testing
+// default is time.Now
+func BreakerClock(now func() time.Time) CircuitBreakerOption {
+	if now == nil {
+		return func(*CircuitBreaker) {}
+	}
+	return func(cb *CircuitBreaker) {
+		cb.now = now
+	}
+}
+
+// State returns the current state of the breaker
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.refresh(cb.now())
+	return cb.state
+}
+
+// Reset forces the breaker to the closed state and clears all counters
+func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.setState(CircuitClosed, cb.now())
+}
+
+// allow reports whether a call may proceed; the returned generation must be passed to record
+func (cb *CircuitBreaker) allow() (uint64, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.refresh(cb.now())
+
+	switch cb.state {
+	case CircuitOpen:
+		return cb.generation, ErrCircuitOpen
+	case CircuitHalfOpen:
+		if cb.inFlight >= cb.halfOpenProbes {
+			return cb.generation, ErrCircuitOpen
+		}
+		cb.inFlight++
+	}
+
+	return cb.generation, nil
+}
+
+// record reports the outcome of a call allowed in `generation`
+func (cb *CircuitBreaker) record(generation uint64, success bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := cb.now()
+	cb.refresh(now)
+
+	// result of a call started before the last state change
+	if generation != cb.generation {
+		return
+	}
+
+	switch cb.state {
+	case CircuitClosed:
+		cb.requests++
+		if success {
+			cb.consecutive = 0
+			return
+		}
+		cb.failures++
+		cb.consecutive++
+		if cb.shouldTrip() {
+			cb.setState(CircuitOpen, now)
+		}
+	case CircuitHalfOpen:
+		cb.inFlight--
+		if !success {
+			cb.setState(CircuitOpen, now)
+			return
+		}
+		cb.probeOK++
+		if cb.probeOK >= cb.halfOpenProbes {
+			cb.setState(CircuitClosed, now)
+		}
+	}
+}
+
+// release ends a call allowed in `generation` without counting its outcome,
+// e.g. of a call cancelled by the caller
+func (cb *CircuitBreaker) release(generation uint64) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if generation == cb.generation && cb.state == CircuitHalfOpen {
+		cb.inFlight--
+	}
+}
+
+func (cb *CircuitBreaker) shouldTrip() bool {
+	if cb.consecutiveFailures > 0 && cb.consecutive >= cb.consecutiveFailures {
+		return true
+	}
+
+	return cb.failureRatio > 0 &&
+		cb.requests >= cb.minRequests &&
+		float64(cb.failures)/float64(cb.requests) >= cb.failureRatio
+}
+
+// refresh applies time based transitions, must be called with mu held
+func (cb *CircuitBreaker) refresh(now time.Time) {
+	switch cb.state {
+	case CircuitOpen:
+		if now.Sub(cb.changedAt) >= cb.coolDown {
+			cb.setState(CircuitHalfOpen, now)
+		}
+	case CircuitClosed:
+		if cb.window > 0 && now.Sub(cb.windowAt) >= cb.window {
+			// a new window keeps the generation, so the outcomes of calls in flight still count
+			cb.windowAt = now
+			cb.requests = 0
+			cb.failures = 0
+		}
+	}
+}
+
+// setState switches the breaker to `state` and clears counters, must be called with mu held
+func (cb *CircuitBreaker) setState(state CircuitState, now time.Time) {
+	cb.state = state
+	cb.generation++
+	cb.changedAt = now
+	cb.windowAt = now
+	cb.requests = 0
+	cb.failures = 0
+	cb.consecutive = 0
+	cb.inFlight = 0
+	cb.probeOK = 0
+}
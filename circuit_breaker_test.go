@@ -0,0 +1,290 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// After implements Timer, it advances the clock by `d` and fires immediately
+func (c *testClock) After(d time.Duration) <-chan time.Time {
+	c.Advance(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.Now()
+	return ch
+}
+
+func TestCircuitBreakerConsecutiveFailures(t *testing.T) {
+	clock := &testClock{now: time.Unix(0, 0)}
+	cb := NewCircuitBreaker(
+		BreakerConsecutiveFailures(3),
+		BreakerCoolDown(time.Minute),
+		BreakerClock(clock.Now),
+	)
+
+	for i := 0; i < 2; i++ {
+		g, err := cb.allow()
+		assert.NoError(t, err)
+		cb.record(g, false)
+	}
+	g, _ := cb.allow()
+	cb.record(g, true)
+	assert.Equal(t, CircuitClosed, cb.State(), "success resets consecutive failures")
+
+	for i := 0; i < 3; i++ {
+		g, err := cb.allow()
+		assert.NoError(t, err)
+		cb.record(g, false)
+	}
+	assert.Equal(t, CircuitOpen, cb.State())
+
+	_, err := cb.allow()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, CircuitHalfOpen, cb.State())
+
+	g, err = cb.allow()
+	assert.NoError(t, err)
+	_, err = cb.allow()
+	assert.ErrorIs(t, err, ErrCircuitOpen, "only one probe in half-open")
+
+	cb.record(g, true)
+	assert.Equal(t, CircuitClosed, cb.State())
+}
+
+func TestCircuitBreakerFailureRatio(t *testing.T) {
+	cb := NewCircuitBreaker(
+		BreakerConsecutiveFailures(0),
+		BreakerFailureRatio(0.5, 4),
+	)
+
+	results := []bool{false, true, true}
+	for _, success := range results {
+		g, err := cb.allow()
+		assert.NoError(t, err)
+		cb.record(g, success)
+	}
+	assert.Equal(t, CircuitClosed, cb.State(), "not enough requests")
+
+	g, _ := cb.allow()
+	cb.record(g, false)
+	assert.Equal(t, CircuitOpen, cb.State(), "2 of 4 requests failed")
+}
+
+func TestCircuitBreakerWindow(t *testing.T) {
+	t.Run("failure ratio", func(t *testing.T) {
+		clock := &testClock{now: time.Unix(0, 0)}
+		cb := NewCircuitBreaker(
+			BreakerConsecutiveFailures(0),
+			BreakerFailureRatio(0.5, 3),
+			BreakerWindow(time.Second),
+			BreakerClock(clock.Now),
+		)
+
+		results := []bool{false, false, true, false, true}
+		for i, success := range results {
+			if i == 2 {
+				clock.Advance(time.Second)
+			}
+			g, err := cb.allow()
+			assert.NoError(t, err)
+			cb.record(g, success)
+		}
+		assert.Equal(t, CircuitClosed, cb.State(), "failure counters are cleared after window")
+	})
+
+	t.Run("consecutive failures", func(t *testing.T) {
+		clock := &testClock{now: time.Unix(0, 0)}
+		cb := NewCircuitBreaker(
+			BreakerConsecutiveFailures(3),
+			BreakerWindow(time.Second),
+			BreakerClock(clock.Now),
+		)
+
+		g, _ := cb.allow()
+		cb.record(g, false)
+		inFlight, _ := cb.allow()
+		clock.Advance(time.Second)
+		g, _ = cb.allow()
+		cb.record(g, false)
+		assert.Equal(t, CircuitClosed, cb.State())
+
+		cb.record(inFlight, false)
+		assert.Equal(t, CircuitOpen, cb.State(), "slow failures trip the breaker across windows")
+	})
+}
+
+func TestCircuitBreakerHalfOpenFailure(t *testing.T) {
+	clock := &testClock{now: time.Unix(0, 0)}
+	cb := NewCircuitBreaker(
+		BreakerConsecutiveFailures(1),
+		BreakerCoolDown(time.Second),
+		BreakerHalfOpenProbes(2),
+		BreakerClock(clock.Now),
+	)
+
+	g, _ := cb.allow()
+	cb.record(g, false)
+	clock.Advance(time.Second)
+
+	g1, err := cb.allow()
+	assert.NoError(t, err)
+	g2, err := cb.allow()
+	assert.NoError(t, err)
+
+	cb.record(g1, false)
+	assert.Equal(t, CircuitOpen, cb.State())
+
+	// late result of a probe from the previous half-open state is ignored
+	cb.record(g2, true)
+	assert.Equal(t, CircuitOpen, cb.State())
+
+	cb.Reset()
+	assert.Equal(t, CircuitClosed, cb.State())
+}
+
+func TestCircuitBreakerProbePanics(t *testing.T) {
+	clock := &testClock{now: time.Unix(0, 0)}
+	cb := NewCircuitBreaker(
+		BreakerConsecutiveFailures(1),
+		BreakerCoolDown(time.Second),
+		BreakerClock(clock.Now),
+	)
+	retrier := New(Attempts(1), WithCircuitBreaker(cb))
+
+	g, _ := cb.allow()
+	cb.record(g, false)
+	clock.Advance(time.Second)
+
+	assert.Panics(t, func() {
+		_ = retrier.Do(func() error { panic("probe") })
+	})
+	assert.Equal(t, CircuitOpen, cb.State(), "a panicking probe counts as failed")
+
+	clock.Advance(time.Second)
+	err := retrier.Do(func() error { return nil })
+	assert.NoError(t, err, "the probe slot is not leaked")
+	assert.Equal(t, CircuitClosed, cb.State())
+}
+
+func TestCircuitBreakerCallerCancellation(t *testing.T) {
+	clock := &testClock{now: time.Unix(0, 0)}
+	cb := NewCircuitBreaker(
+		BreakerConsecutiveFailures(1),
+		BreakerCoolDown(time.Second),
+		BreakerClock(clock.Now),
+	)
+	retrier := New(Attempts(1), WithCircuitBreaker(cb))
+
+	cancelled := func() error {
+		ctx, cancel := context.WithCancel(context.Background())
+		return retrier.DoWithContext(ctx, func() error {
+			cancel()
+			return context.Canceled
+		})
+	}
+
+	assert.ErrorIs(t, cancelled(), context.Canceled)
+	assert.Equal(t, CircuitClosed, cb.State(), "cancellations are not counted as failures")
+
+	g, _ := cb.allow()
+	cb.record(g, false)
+	clock.Advance(time.Second)
+
+	assert.ErrorIs(t, cancelled(), context.Canceled)
+	assert.Equal(t, CircuitHalfOpen, cb.State(), "a cancelled probe does not re-open the breaker")
+
+	err := retrier.Do(func() error { return nil })
+	assert.NoError(t, err, "the probe slot of the cancelled probe is released")
+	assert.Equal(t, CircuitClosed, cb.State())
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	cb := NewCircuitBreaker(BreakerConsecutiveFailures(3))
+	testErr := errors.New("test")
+
+	calls := 0
+	err := New(
+		Attempts(5),
+		Delay(time.Nanosecond),
+		WithCircuitBreaker(cb),
+	).Do(
+		func() error {
+			calls++
+			return testErr
+		},
+	)
+	assert.Equal(t, 3, calls, "function is not called while circuit is open")
+	assert.ErrorIs(t, err, testErr)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Len(t, unwrapStopError(err), 4)
+
+	// breaker is shared between retriers
+	_, err = NewWithData[int](
+		WithCircuitBreaker(cb),
+		LastErrorOnly(true),
+	).Do(
+		func() (int, error) {
+			calls++
+			return 1, nil
+		},
+	)
+	assert.Equal(t, ErrCircuitOpen, unwrapStopError(err))
+	assert.Equal(t, 3, calls)
+
+	err = New(
+		Attempts(0),
+		WithCircuitBreaker(cb),
+	).Do(
+		func() error {
+			calls++
+			return nil
+		},
+	)
+	assert.Equal(t, Error{ErrCircuitOpen}, unwrapStopError(err))
+	assert.Equal(t, 3, calls)
+}
+
+func TestCircuitBreakerConcurrent(t *testing.T) {
+	cb := NewCircuitBreaker(BreakerConsecutiveFailures(1000))
+	retrier := New(Attempts(2), Delay(time.Nanosecond), WithCircuitBreaker(cb))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = retrier.Do(func() error {
+				if i%2 == 0 {
+					return errors.New("test")
+				}
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, CircuitClosed, cb.State())
+}
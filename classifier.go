@@ -0,0 +1,203 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+)
+
+type decisionKind int
+
+const (
+	decisionRetry decisionKind = iota
+	decisionGiveUp
+	decisionRetryWithDelay
+	decisionRetryWithBudget
+)
+
+// Decision is what a Classifier decides for the error of a failed attempt
+type Decision struct {
+	kind   decisionKind
+	delay  time.Duration
+	budget uint
+}
+
+var (
+	// Retry retries the error like any other recoverable error
+	Retry = Decision{kind: decisionRetry}
+	// GiveUp stops retrying on the error, like Unrecoverable
+	GiveUp = Decision{kind: decisionGiveUp}
+)
+
+// RetryWithDelay retries the error after `delay` instead of the delay computed by DelayType
+// (MaxDelay still applies)
+func RetryWithDelay(delay time.Duration) Decision {
+	return Decision{kind: decisionRetryWithDelay, delay: delay}
+}
+
+// RetryWithBudget retries the error until the errors matched by the rule were returned `budget` times,
+// like AttemptsForError for all errors matched by the rule.
+// The budget is counted per retry operation and stops with StopReasonAttemptsForErrorExhausted.
+func RetryWithBudget(budget uint) Decision {
+	return Decision{kind: decisionRetryWithBudget, budget: budget}
+}
+
+// Rule maps the errors it matches to a Decision, see Classifier
+type Rule struct {
+	match    func(error) bool
+	decision Decision
+}
+
+// OnErrorIs matches errors for which errors.Is(err, target) is true
+func OnErrorIs(target error, decision Decision) Rule {
+	return Rule{
+		match:    func(err error) bool { return errors.Is(err, target) },
+		decision: decision,
+	}
+}
+
+// OnErrorAs matches errors for which errors.As finds an error of type E
+//
+//	retry.OnErrorAs[*net.DNSError](retry.RetryWithDelay(time.Second))
+func OnErrorAs[E error](decision Decision) Rule {
+	return Rule{
+		match: func(err error) bool {
+			var target E
+			return errors.As(err, &target)
+		},
+		decision: decision,
+	}
+}
+
+// OnErrorIf matches errors for which `predicate` returns true
+func OnErrorIf(predicate func(err error) bool, decision Decision) Rule {
+	return Rule{match: predicate, decision: decision}
+}
+
+// OnAnyError matches every error, as the last rule it decides errors not matched by the other rules
+func OnAnyError(decision Decision) Rule {
+	return Rule{
+		match:    func(error) bool { return true },
+		decision: decision,
+	}
+}
+
+// Classifier decides the errors of failed attempts by rules, evaluated in order; the first matching rule wins.
+// It is set by WithClassifier and replaces RetryIf, AttemptsForError and Unrecoverable
+// for the errors it matches.
+//
+//	classifier := retry.NewClassifier(
+//		retry.OnErrorIs(ErrQuota, retry.RetryWithBudget(2)),
+//		retry.OnErrorAs[*net.DNSError](retry.RetryWithDelay(time.Second)),
+//	).With(retry.TransientErrors()...).With(
+//		retry.OnAnyError(retry.GiveUp),
+//	)
+type Classifier struct {
+	rules []Rule
+}
+
+// NewClassifier creates a new Classifier evaluating `rules` in order
+func NewClassifier(rules ...Rule) *Classifier {
+	return &Classifier{rules: append([]Rule(nil), rules...)}
+}
+
+// With returns a new Classifier evaluating `rules` after the rules of c
+func (c *Classifier) With(rules ...Rule) *Classifier {
+	combined := make([]Rule, 0, len(c.rules)+len(rules))
+	combined = append(combined, c.rules...)
+	return &Classifier{rules: append(combined, rules...)}
+}
+
+// Classify returns the Decision of the first rule matching `err`,
+// false if no rule matches
+func (c *Classifier) Classify(err error) (Decision, bool) {
+	if i := c.match(err); i >= 0 {
+		return c.rules[i].decision, true
+	}
+	return Decision{}, false
+}
+
+// match returns the index of the first rule matching `err`, -1 if no rule matches
+func (c *Classifier) match(err error) int {
+	for i, rule := range c.rules {
+		if rule.match != nil && rule.match(err) {
+			return i
+		}
+	}
+	return -1
+}
+
+// RetryNetworkTimeouts retries net.Error errors reporting a timeout
+func RetryNetworkTimeouts() Rule {
+	return OnErrorIf(func(err error) bool {
+		var netErr net.Error
+		return errors.As(err, &netErr) && netErr.Timeout()
+	}, Retry)
+}
+
+// RetryConnectionErrors retries refused and reset connections (syscall.ECONNREFUSED and syscall.ECONNRESET)
+func RetryConnectionErrors() Rule {
+	return OnErrorIf(func(err error) bool {
+		return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET)
+	}, Retry)
+}
+
+// RetryUnexpectedEOF retries io.ErrUnexpectedEOF, e.g. of connections closed in the middle of a response
+func RetryUnexpectedEOF() Rule {
+	return OnErrorIs(io.ErrUnexpectedEOF, Retry)
+}
+
+// RetryDeadlineExceeded retries context.DeadlineExceeded, e.g. of attempts timed out by AttemptTimeout.
+// The deadline of the context of the retrier still stops the retry.
+func RetryDeadlineExceeded() Rule {
+	return OnErrorIs(context.DeadlineExceeded, Retry)
+}
+
+// TransientErrors returns the rules retrying transient errors:
+// RetryNetworkTimeouts, RetryConnectionErrors, RetryUnexpectedEOF and RetryDeadlineExceeded
+func TransientErrors() []Rule {
+	return []Rule{
+		RetryNetworkTimeouts(),
+		RetryConnectionErrors(),
+		RetryUnexpectedEOF(),
+		RetryDeadlineExceeded(),
+	}
+}
+
+// classify applies the Classifier to the error of a failed attempt,
+// false if the error is left to RetryIf and AttemptsForError.
+// The delay requested by RetryWithDelay is used by the next computeDelay.
+func (s *retryState) classify(err error) (StopReason, bool) {
+	s.delayClassified = false
+	if s.classifier == nil || err == ErrUnsatisfactoryResult || errors.Is(err, ErrCircuitOpen) {
+		return StopReasonNone, false
+	}
+
+	i := s.classifier.match(err)
+	if i < 0 {
+		return StopReasonNone, false
+	}
+
+	decision := s.classifier.rules[i].decision
+	switch decision.kind {
+	case decisionGiveUp:
+		if !IsRecoverable(err) {
+			return StopReasonUnrecoverable, true
+		}
+		return StopReasonClassifier, true
+	case decisionRetryWithDelay:
+		s.delayClassified, s.classifiedDelay = true, decision.delay
+	case decisionRetryWithBudget:
+		if s.ruleCounts == nil {
+			s.ruleCounts = make([]uint, len(s.classifier.rules))
+		}
+		s.ruleCounts[i]++
+		if s.ruleCounts[i] >= decision.budget {
+			return StopReasonAttemptsForErrorExhausted, true
+		}
+	}
+	return StopReasonNone, true
+}
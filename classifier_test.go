@@ -0,0 +1,193 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifierClassify(t *testing.T) {
+	errQuota := errors.New("quota")
+	testErr := errors.New("test")
+
+	classifier := NewClassifier(
+		OnErrorIs(errQuota, RetryWithBudget(2)),
+		OnErrorAs[*os.PathError](GiveUp),
+		OnErrorIf(func(err error) bool { return err.Error() == "slow" }, RetryWithDelay(time.Second)),
+	)
+
+	tests := []struct {
+		name     string
+		err      error
+		matched  bool
+		expected Decision
+	}{
+		{"is", fmt.Errorf("wrapped: %w", errQuota), true, RetryWithBudget(2)},
+		{"as", &os.PathError{Op: "open", Err: testErr}, true, GiveUp},
+		{"if", errors.New("slow"), true, RetryWithDelay(time.Second)},
+		{"first rule wins", Error{errQuota, &os.PathError{}}, true, RetryWithBudget(2)},
+		{"no match", testErr, false, Decision{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, matched := classifier.Classify(tt.err)
+			assert.Equal(t, tt.matched, matched)
+			assert.Equal(t, tt.expected, decision)
+		})
+	}
+
+	t.Run("with", func(t *testing.T) {
+		extended := classifier.With(OnAnyError(Retry))
+		decision, matched := extended.Classify(testErr)
+		assert.True(t, matched)
+		assert.Equal(t, Retry, decision)
+
+		_, matched = classifier.Classify(testErr)
+		assert.False(t, matched, "With does not change the original classifier")
+	})
+}
+
+func TestTransientErrors(t *testing.T) {
+	classifier := NewClassifier(TransientErrors()...)
+
+	tests := []struct {
+		name    string
+		err     error
+		matched bool
+	}{
+		{"network timeout", &net.DNSError{Err: "timeout", IsTimeout: true}, true},
+		{"network error", &net.DNSError{Err: "no such host", IsNotFound: true}, false},
+		{"connection refused", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, true},
+		{"connection reset", &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, true},
+		{"unexpected EOF", fmt.Errorf("reading body: %w", io.ErrUnexpectedEOF), true},
+		{"EOF", io.EOF, false},
+		{"deadline exceeded", context.DeadlineExceeded, true},
+		{"canceled", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, matched := classifier.Classify(tt.err)
+			assert.Equal(t, tt.matched, matched)
+			if matched {
+				assert.Equal(t, Retry, decision)
+			}
+		})
+	}
+}
+
+func TestWithClassifier(t *testing.T) {
+	errQuota := errors.New("quota")
+	errFatal := errors.New("fatal")
+	testErr := errors.New("test")
+
+	tests := []struct {
+		name     string
+		rules    []Rule
+		opts     []Option
+		errs     []error
+		attempts uint
+		reason   StopReason
+		delays   []time.Duration
+	}{
+		{
+			name:     "give up",
+			rules:    []Rule{OnErrorIs(errFatal, GiveUp)},
+			errs:     []error{testErr, errFatal},
+			attempts: 2,
+			reason:   StopReasonClassifier,
+		},
+		{
+			name:     "give up unrecoverable",
+			rules:    []Rule{OnAnyError(GiveUp)},
+			errs:     []error{Unrecoverable(testErr)},
+			attempts: 1,
+			reason:   StopReasonUnrecoverable,
+		},
+		{
+			name:     "retry replaces unrecoverable",
+			rules:    []Rule{OnErrorIs(testErr, Retry)},
+			errs:     []error{Unrecoverable(testErr)},
+			attempts: 5,
+			reason:   StopReasonAttemptsExhausted,
+		},
+		{
+			name:     "retry replaces retry if",
+			rules:    []Rule{OnErrorIs(testErr, Retry)},
+			opts:     []Option{RetryIf(func(error) bool { return false })},
+			errs:     []error{testErr},
+			attempts: 5,
+			reason:   StopReasonAttemptsExhausted,
+		},
+		{
+			name:     "unmatched errors use retry if",
+			rules:    []Rule{OnErrorIs(errQuota, Retry)},
+			opts:     []Option{RetryIf(func(err error) bool { return err != errFatal })},
+			errs:     []error{errQuota, testErr, errFatal},
+			attempts: 3,
+			reason:   StopReasonRetryIf,
+		},
+		{
+			name:     "budget",
+			rules:    []Rule{OnErrorIs(errQuota, RetryWithBudget(2))},
+			errs:     []error{errQuota, testErr, errQuota, testErr},
+			attempts: 3,
+			reason:   StopReasonAttemptsForErrorExhausted,
+		},
+		{
+			name:     "budget replaces attempts for error",
+			rules:    []Rule{OnErrorIs(errQuota, RetryWithBudget(3))},
+			opts:     []Option{AttemptsForError(1, errQuota)},
+			errs:     []error{errQuota},
+			attempts: 3,
+			reason:   StopReasonAttemptsForErrorExhausted,
+		},
+		{
+			name:     "delay",
+			rules:    []Rule{OnErrorIs(errQuota, RetryWithDelay(time.Minute))},
+			opts:     []Option{MaxDelay(30 * time.Second)},
+			errs:     []error{errQuota, testErr},
+			attempts: 5,
+			reason:   StopReasonAttemptsExhausted,
+			delays:   []time.Duration{30 * time.Second, time.Millisecond, 30 * time.Second, time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var delays []time.Duration
+			observer := &recordingObserver{}
+			opts := append([]Option{
+				Attempts(5),
+				Delay(time.Millisecond),
+				DelayType(FixedDelay),
+				WithTimer(recordingTimer{&delays}),
+				WithObserver(observer),
+				WithClassifier(NewClassifier(tt.rules...)),
+			}, tt.opts...)
+
+			var attempts uint
+			err := New(opts...).Do(func() error {
+				err := tt.errs[int(attempts)%len(tt.errs)]
+				attempts++
+				return err
+			})
+
+			assert.Error(t, err)
+			assert.Equal(t, tt.attempts, attempts)
+			assert.Equal(t, tt.reason, observer.reason)
+			if tt.delays != nil {
+				assert.Equal(t, tt.delays, delays)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Hedge executes the retryable function as hedged requests configured by the Hedging option.
+//
+// The first copy of the call starts immediately, another copy starts whenever no copy has
+// returned within the hedge delay (with at most maxParallel copies in flight), or after the
+// regular retry delay when every copy in flight has failed. The first successful result wins
+// and the contexts of all other copies are cancelled (the cancelled copies are not reported as failed
+// to the circuit breaker set by WithCircuitBreaker). The total number of copies is limited by
+// Attempts; errors of all failed copies are collected into the Error wrapped by the returned StopError.
+//
+// Only use Hedge for idempotent operations.
+func (r *Retrier) Hedge(retryableFunc RetryableFuncWithContext) error {
+	retryableFuncWithData := func(ctx context.Context) (any, error) {
+		return nil, retryableFunc(ctx)
+	}
+
+	_, err := doHedged(r.retrierCore, retryableFuncWithData)
+	return err
+}
+
+// Hedge executes the retryable function as hedged requests configured by the Hedging option.
+// See Retrier.Hedge for details.
+func (r *RetrierWithData[T]) Hedge(retryableFunc RetryableFuncWithDataAndContext[T]) (T, error) {
+	return doHedged(r.retrierCore, retryableFunc)
+}
+
+type hedgeResult[T any] struct {
+	t       T
+	err     error
+	attempt uint
+	start   time.Time
+}
+
+func doHedged[T any](r *retrierCore, retryableFunc RetryableFuncWithDataAndContext[T]) (T, error) {
+	var emptyT T
+
+	s := newRetryState(r, r.context)
+
+	if err := context.Cause(s.ctx); err != nil {
+		err = s.giveUp(StopReasonContext, err)
+		return emptyT, err
+	}
+
+	maxParallel := r.hedgeMaxParallel
+	if maxParallel == 0 {
+		maxParallel = 1
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	// at most maxParallel copies are in flight, so losers never block on send
+	results := make(chan hedgeResult[T], maxParallel)
+
+	var n, inFlight uint
+	launch := func() {
+		n++
+		inFlight++
+		attempt := n
+		start := s.attemptStart(attempt)
+		go func() {
+			t, err := runAttempt(r, ctx, retryableFunc)
+			results <- hedgeResult[T]{t, err, attempt, start}
+		}()
+	}
+
+	// result holds the value of the last attempt rejected by RetryIfResult
+	var result T
+
+	errorLog := Error{}
+	fail := func(reason StopReason, err error) (T, error) {
+		return result, s.giveUp(reason, err)
+	}
+
+	launch()
+	var next <-chan time.Time
+	// retrying tells that next is the delay before a retry, which is already withdrawn from the budget
+	var retrying bool
+	if inFlight < maxParallel {
+		next = r.after(ctx, r.hedgeDelay)
+	}
+
+	for {
+		select {
+		case res := <-results:
+			inFlight--
+			result = emptyT
+			if res.err == nil {
+				if r.retryIfResult == nil || !r.retryIfResult(res.t, nil) {
+					s.attemptEnd(res.attempt, res.start, nil, 0, StopReasonSuccess)
+					if n == 1 && r.budget != nil {
+						r.budget.Deposit()
+					}
+					return res.t, nil
+				}
+				result, res.err = res.t, ErrUnsatisfactoryResult
+			}
+
+			errorLog = s.appendError(errorLog, unpackUnrecoverable(res.err))
+
+			delay, reason := failureDecision(&s, n, res.start, res.t, res.err, inFlight > 0)
+			s.attemptEnd(res.attempt, res.start, res.err, delay, reason)
+
+			switch {
+			case reason != StopReasonNone:
+				return fail(reason, s.failure(errorLog, reasonError(reason)))
+			case inFlight == 0:
+				next, retrying = r.after(ctx, delay), true
+			case next == nil && (r.attempts == 0 || n < r.attempts):
+				next = r.after(ctx, r.hedgeDelay)
+			}
+		case <-next:
+			next = nil
+			retried := retrying
+			retrying = false
+			if r.attempts > 0 && n >= r.attempts {
+				continue
+			}
+			// the copies started by the hedge delay are withdrawn from the budget when they start
+			if !retried && r.budget != nil && !r.budget.Withdraw() {
+				continue
+			}
+
+			launch()
+			if inFlight < maxParallel {
+				next = r.after(ctx, r.hedgeDelay)
+			}
+		case <-s.ctx.Done():
+			return fail(StopReasonContext, s.contextFailure(errorLog))
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHedge(t *testing.T) {
+	t.Run("first success wins and losers are cancelled", func(t *testing.T) {
+		var calls int32
+		cancelled := make(chan struct{})
+		v, err := NewWithData[int](
+			Hedging(2, 10*time.Millisecond),
+		).Hedge(
+			func(ctx context.Context) (int, error) {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					<-ctx.Done()
+					close(cancelled)
+					return 0, ctx.Err()
+				}
+				return 2, nil
+			},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, v)
+
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("slow copy was not cancelled")
+		}
+	})
+
+	t.Run("no hedge when first copy is fast", func(t *testing.T) {
+		var calls int32
+		err := New(
+			Hedging(3, time.Second),
+		).Hedge(
+			func(ctx context.Context) error {
+				atomic.AddInt32(&calls, 1)
+				return nil
+			},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("errors of all copies are collected", func(t *testing.T) {
+		var calls, inFlight, maxInFlight int32
+		err := New(
+			Attempts(4),
+			Delay(time.Nanosecond),
+			Hedging(2, 0),
+		).Hedge(
+			func(ctx context.Context) error {
+				current := atomic.AddInt32(&inFlight, 1)
+				defer atomic.AddInt32(&inFlight, -1)
+				for {
+					old := atomic.LoadInt32(&maxInFlight)
+					if current <= old || atomic.CompareAndSwapInt32(&maxInFlight, old, current) {
+						break
+					}
+				}
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(5 * time.Millisecond)
+				return errors.New("test")
+			},
+		)
+		assert.Error(t, err)
+		assert.Len(t, unwrapStopError(err), 4)
+		assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+		assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+	})
+
+	t.Run("unrecoverable error stops hedging", func(t *testing.T) {
+		testErr := errors.New("test")
+		var calls int32
+		err := New(
+			Hedging(2, time.Second),
+		).Hedge(
+			func(ctx context.Context) error {
+				atomic.AddInt32(&calls, 1)
+				return Unrecoverable(testErr)
+			},
+		)
+		assert.Equal(t, Error{testErr}, unwrapStopError(err))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("attempts for error", func(t *testing.T) {
+		testErr := errors.New("test")
+		var calls int32
+		err := New(
+			Attempts(5),
+			AttemptsForError(1, testErr),
+			Delay(time.Nanosecond),
+			Hedging(2, time.Second),
+		).Hedge(
+			func(ctx context.Context) error {
+				atomic.AddInt32(&calls, 1)
+				return testErr
+			},
+		)
+		assert.Equal(t, Error{testErr}, unwrapStopError(err))
+		assert.Equal(t, StopReasonAttemptsForErrorExhausted, ReasonOf(err))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("rejected result is returned", func(t *testing.T) {
+		var calls int32
+		status, err := NewWithData[string](
+			Attempts(2),
+			Delay(time.Nanosecond),
+			Hedging(2, time.Second),
+		).RetryIfResult(func(status string, err error) bool {
+			return err != nil || status == "pending"
+		}).Hedge(
+			func(ctx context.Context) (string, error) {
+				atomic.AddInt32(&calls, 1)
+				return "pending", nil
+			},
+		)
+		assert.Equal(t, "pending", status)
+		assert.Equal(t, Error{ErrUnsatisfactoryResult, ErrUnsatisfactoryResult}, unwrapStopError(err))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("uses timer for hedge delay", func(t *testing.T) {
+		timer := &testTimer{}
+		var calls int32
+		_, err := NewWithData[int](
+			Hedging(2, time.Millisecond),
+			WithTimer(timer),
+		).Hedge(
+			func(ctx context.Context) (int, error) {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					<-ctx.Done()
+					return 0, ctx.Err()
+				}
+				return 1, nil
+			},
+		)
+		assert.NoError(t, err)
+		assert.True(t, timer.called)
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := New(
+			Context(ctx),
+			Hedging(2, time.Millisecond),
+			LastErrorOnly(true),
+		).Hedge(
+			func(ctx context.Context) error {
+				cancel()
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		)
+		assert.Equal(t, context.Canceled, unwrapStopError(err))
+	})
+}
+
+func TestHedgeWithCircuitBreaker(t *testing.T) {
+	cb := NewCircuitBreaker(BreakerFailureRatio(0.5, 4))
+	retrier := New(Hedging(2, 5*time.Millisecond), WithCircuitBreaker(cb))
+
+	for i := 0; i < 4; i++ {
+		var calls int32
+		err := retrier.Hedge(func(ctx context.Context) error {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+	}
+
+	// the slower copies cancelled by the hedge finish in the background
+	time.Sleep(10 * time.Millisecond)
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	assert.Equal(t, CircuitClosed, cb.state)
+	assert.Equal(t, uint(4), cb.requests, "only the winning copies are counted")
+	assert.Equal(t, uint(0), cb.failures, "cancelled copies are not counted as failures")
+}
@@ -43,7 +43,8 @@ func TestLastError(t *testing.T) {
 		assert.Error(t, retryErr)
 
 		// New v5.0.0 way - option 2 (if you need the last error):
-		if e, ok := retryErr.(Error); ok {
+		var e Error
+		if errors.As(retryErr, &e) {
 			lastErr := e.LastError()
 			assert.NotNil(t, lastErr)
 			assert.Contains(t, lastErr.Error(), "operation failed")
@@ -0,0 +1,42 @@
+package retry
+
+import "time"
+
+// Metrics collects counters and delays of retry operations, e.g. as Prometheus metrics.
+// A retrier may be used by many goroutines at once, so implementations must be safe for concurrent use.
+type Metrics interface {
+	// IncAttempts is called before every attempt
+	IncAttempts()
+	// IncRetries is called for every retry, i.e. each attempt after the first one
+	IncRetries()
+	// IncSuccesses is called when a retry operation succeeds
+	IncSuccesses()
+	// IncGiveUps is called when a retry operation ends without success
+	IncGiveUps(reason StopReason)
+	// ObserveDelay is called with every delay before the next attempt
+	ObserveDelay(delay time.Duration)
+}
+
+// metricsObserver adapts Metrics to Observer
+type metricsObserver struct {
+	metrics Metrics
+}
+
+func (o metricsObserver) OnAttemptStart(uint) {
+	o.metrics.IncAttempts()
+}
+
+func (o metricsObserver) OnAttemptEnd(AttemptInfo) {}
+
+func (o metricsObserver) OnWait(_ uint, delay time.Duration) {
+	o.metrics.IncRetries()
+	o.metrics.ObserveDelay(delay)
+}
+
+func (o metricsObserver) OnGiveUp(reason StopReason, _ error) {
+	o.metrics.IncGiveUps(reason)
+}
+
+func (o metricsObserver) OnSuccess(uint) {
+	o.metrics.IncSuccesses()
+}
@@ -0,0 +1,86 @@
+package retry
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testMetrics struct {
+	mu        sync.Mutex
+	attempts  int
+	retries   int
+	successes int
+	giveUps   map[StopReason]int
+	delays    []time.Duration
+}
+
+func (m *testMetrics) IncAttempts() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.attempts++
+}
+
+func (m *testMetrics) IncRetries() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries++
+}
+
+func (m *testMetrics) IncSuccesses() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.successes++
+}
+
+func (m *testMetrics) IncGiveUps(reason StopReason) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.giveUps == nil {
+		m.giveUps = map[StopReason]int{}
+	}
+	m.giveUps[reason]++
+}
+
+func (m *testMetrics) ObserveDelay(delay time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.delays = append(m.delays, delay)
+}
+
+func TestWithMetrics(t *testing.T) {
+	metrics := &testMetrics{}
+	retrier := New(
+		Attempts(3),
+		Delay(time.Millisecond),
+		DelayType(BackOffDelay),
+		AttemptsForError(2, os.ErrInvalid),
+		WithMetrics(metrics),
+	)
+
+	err := retrier.Do(func() error { return errors.New("test") })
+	assert.Error(t, err)
+
+	err = retrier.Do(func() error { return os.ErrInvalid })
+	assert.Error(t, err)
+
+	err = retrier.Do(func() error { return Unrecoverable(errors.New("test")) })
+	assert.Error(t, err)
+
+	err = retrier.Do(func() error { return nil })
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3+2+1+1, metrics.attempts)
+	assert.Equal(t, 2+1, metrics.retries)
+	assert.Equal(t, 1, metrics.successes)
+	assert.Equal(t, map[StopReason]int{
+		StopReasonAttemptsExhausted:         1,
+		StopReasonAttemptsForErrorExhausted: 1,
+		StopReasonUnrecoverable:             1,
+	}, metrics.giveUps)
+	assert.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond, time.Millisecond}, metrics.delays)
+}
@@ -0,0 +1,279 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// StopReason describes why a retry sequence ended
+type StopReason int
+
+const (
+	// StopReasonNone means the retry sequence continues with another attempt
+	StopReasonNone StopReason = iota
+	// StopReasonSuccess means an attempt succeeded
+	StopReasonSuccess
+	// StopReasonAttemptsExhausted means all attempts set by Attempts failed
+	StopReasonAttemptsExhausted
+	// StopReasonAttemptsForErrorExhausted means the attempts set by AttemptsForError were used up
+	StopReasonAttemptsForErrorExhausted
+	// StopReasonUnrecoverable means the retried function returned an error wrapped by Unrecoverable
+	StopReasonUnrecoverable
+	// StopReasonRetryIf means RetryIf (or RetryIfResult) rejected the error
+	StopReasonRetryIf
+	// StopReasonContext means the context was cancelled or timed out
+	StopReasonContext
+	// StopReasonCircuitOpen means the circuit breaker was open
+	StopReasonCircuitOpen
+	// StopReasonBudgetExhausted means the retry budget had no tokens left
+	StopReasonBudgetExhausted
+	// StopReasonMaxElapsedTime means the next delay would exceed MaxElapsedTime
+	StopReasonMaxElapsedTime
+	// StopReasonPolicy means the StopPolicy set by Stop stopped the retry
+	StopReasonPolicy
+	// StopReasonWouldExceedDeadline means StopBeforeDeadline stopped the retry
+	// because the next attempt would not fit before the deadline of the context
+	StopReasonWouldExceedDeadline
+	// StopReasonClassifier means a rule of the Classifier set by WithClassifier decided to give up
+	StopReasonClassifier
+)
+
+// String returns a short snake_case name of the reason, usable as a metric label
+func (s StopReason) String() string {
+	switch s {
+	case StopReasonNone:
+		return "none"
+	case StopReasonSuccess:
+		return "success"
+	case StopReasonAttemptsExhausted:
+		return "attempts_exhausted"
+	case StopReasonAttemptsForErrorExhausted:
+		return "attempts_for_error_exhausted"
+	case StopReasonUnrecoverable:
+		return "unrecoverable"
+	case StopReasonRetryIf:
+		return "retry_if"
+	case StopReasonContext:
+		return "context"
+	case StopReasonCircuitOpen:
+		return "circuit_open"
+	case StopReasonBudgetExhausted:
+		return "budget_exhausted"
+	case StopReasonMaxElapsedTime:
+		return "max_elapsed_time"
+	case StopReasonPolicy:
+		return "stop_policy"
+	case StopReasonWouldExceedDeadline:
+		return "would_exceed_deadline"
+	case StopReasonClassifier:
+		return "classifier"
+	default:
+		return "unknown"
+	}
+}
+
+// AttemptInfo describes a finished attempt
+type AttemptInfo struct {
+	// Attempt is the number of the attempt, starting at 1
+	Attempt uint
+	Start   time.Time
+	End     time.Time
+	// Duration is the time spent in the retried function
+	Duration time.Duration
+	// Err is the error returned by the attempt, nil if the attempt succeeded
+	Err error
+	// NextDelay is the delay before the next attempt, 0 if there is no next attempt
+	NextDelay time.Duration
+	// StopReason is StopReasonNone when another attempt follows
+	StopReason StopReason
+}
+
+// Observer is notified about the progress of retry operations.
+// A retrier may be used by many goroutines at once, so implementations must be safe for concurrent use.
+// Embed NopObserver to implement only some of the methods.
+type Observer interface {
+	// OnAttemptStart is called before every attempt
+	OnAttemptStart(attempt uint)
+	// OnAttemptEnd is called after every attempt
+	OnAttemptEnd(info AttemptInfo)
+	// OnWait is called before waiting `delay` for the attempt number `attempt`
+	OnWait(attempt uint, delay time.Duration)
+	// OnGiveUp is called once when the retry sequence ends without success, `err` is the returned error
+	OnGiveUp(reason StopReason, err error)
+	// OnSuccess is called once when the attempt number `attempt` succeeds
+	OnSuccess(attempt uint)
+}
+
+// NopObserver implements Observer with methods doing nothing
+type NopObserver struct{}
+
+// OnAttemptStart implements Observer
+func (NopObserver) OnAttemptStart(uint) {}
+
+// OnAttemptEnd implements Observer
+func (NopObserver) OnAttemptEnd(AttemptInfo) {}
+
+// OnWait implements Observer
+func (NopObserver) OnWait(uint, time.Duration) {}
+
+// OnGiveUp implements Observer
+func (NopObserver) OnGiveUp(StopReason, error) {}
+
+// OnSuccess implements Observer
+func (NopObserver) OnSuccess(uint) {}
+
+// onRetryObserver adapts OnRetryFunc to Observer
+type onRetryObserver struct {
+	NopObserver
+	onRetry OnRetryFunc
+}
+
+// OnAttemptEnd calls onRetry for every failed attempt which passed RetryIf
+func (o onRetryObserver) OnAttemptEnd(info AttemptInfo) {
+	switch info.StopReason {
+	case StopReasonNone, StopReasonAttemptsExhausted, StopReasonAttemptsForErrorExhausted, StopReasonBudgetExhausted,
+		StopReasonMaxElapsedTime, StopReasonPolicy, StopReasonWouldExceedDeadline:
+		o.onRetry(info.Attempt-1, info.Err)
+	}
+}
+
+// retryState holds the state of a single retry sequence
+type retryState struct {
+	*retrierCore
+	ctx      context.Context
+	span     Span
+	delays   *delayState
+	start    time.Time
+	attempts uint
+	records  []AttemptRecord
+
+	// classification of the last failed attempt and the counts of errors matched by RetryWithBudget rules
+	delayClassified bool
+	classifiedDelay time.Duration
+	ruleCounts      []uint
+
+	// failed attempts counted for StopAfterAttemptsForError
+	stopCounts *stopCounts
+
+	// attempts left for the errors of AttemptsForError, copied at the first failed attempt
+	attemptsForErrorLeft map[error]uint
+
+	// errors elided by ErrorHistoryLimit, see trackElided
+	elided          uint
+	elidedErrors    []error
+	elidedKeys      map[interface{}]struct{}
+	elidedSentinels uint
+}
+
+// newRetryState starts a retry sequence, starting a span if a Tracer is set
+func newRetryState(r *retrierCore, ctx context.Context) retryState {
+	s := retryState{retrierCore: r, ctx: ctx}
+	if r.tracer != nil {
+		s.ctx, s.span = r.tracer.Start(ctx, SpanName)
+	}
+	s.start = s.now()
+	return s
+}
+
+// now returns the current time of the Timer if it provides one, the wall clock time otherwise
+func (s *retryState) now() time.Time {
+	if clock, ok := s.timer.(interface{ Now() time.Time }); ok {
+		return clock.Now()
+	}
+	return time.Now()
+}
+
+// attemptStart notifies observers and returns the start time of an attempt
+func (s *retryState) attemptStart(attempt uint) time.Time {
+	s.attempts++
+	for _, o := range s.notify {
+		o.OnAttemptStart(attempt)
+	}
+
+	if s.attempts == 1 {
+		// avoid reading the clock again on the happy path
+		return s.start
+	}
+	return s.now()
+}
+
+// attemptEnd records a failed attempt and notifies observers about a finished attempt
+func (s *retryState) attemptEnd(attempt uint, start time.Time, err error, nextDelay time.Duration, reason StopReason) {
+	notify := len(s.notify) > 0 || s.span != nil
+	if reason == StopReasonSuccess && !notify {
+		return
+	}
+
+	end := s.now()
+	if reason != StopReasonSuccess {
+		s.records, _, _ = appendBounded(s.records, AttemptRecord{
+			Attempt:  attempt,
+			Start:    start,
+			Duration: end.Sub(start),
+			Delay:    nextDelay,
+			Err:      unpackUnrecoverable(err),
+		}, s.recordLimit)
+	}
+	if !notify {
+		return
+	}
+
+	info := AttemptInfo{
+		Attempt:    attempt,
+		Start:      start,
+		End:        end,
+		Duration:   end.Sub(start),
+		Err:        err,
+		NextDelay:  nextDelay,
+		StopReason: reason,
+	}
+	for _, o := range s.notify {
+		o.OnAttemptEnd(info)
+	}
+
+	if s.span != nil {
+		s.addAttemptEvent(info)
+	}
+
+	switch reason {
+	case StopReasonNone:
+		for _, o := range s.notify {
+			o.OnWait(attempt+1, nextDelay)
+		}
+	case StopReasonSuccess:
+		for _, o := range s.notify {
+			o.OnSuccess(attempt)
+		}
+		if s.span != nil {
+			s.span.End(nil)
+		}
+	}
+}
+
+// giveUp notifies observers that the retry sequence ended with `err`.
+// It returns `err` wrapped in a StopError describing the retry sequence.
+func (s *retryState) giveUp(reason StopReason, err error) error {
+	stopErr := &StopError{
+		errs:     []error{err},
+		reason:   reason,
+		attempts: s.attempts,
+		elapsed:  s.now().Sub(s.start),
+		records:  s.records,
+	}
+	if !s.lastErrorOnly {
+		stopErr.elided = s.elided
+		stopErr.elidedAt = int(s.historyLimit / 2)
+		stopErr.errs = append(stopErr.errs, s.elidedErrors...)
+	}
+	err = stopErr
+
+	for _, o := range s.notify {
+		o.OnGiveUp(reason, err)
+	}
+
+	if s.span != nil {
+		s.span.End(err)
+	}
+
+	return err
+}
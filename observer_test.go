@@ -0,0 +1,154 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingObserver struct {
+	mu      sync.Mutex
+	events  []string
+	infos   []AttemptInfo
+	reason  StopReason
+	lastErr error
+}
+
+func (o *recordingObserver) record(format string, args ...interface{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, fmt.Sprintf(format, args...))
+}
+
+func (o *recordingObserver) OnAttemptStart(attempt uint) {
+	o.record("start %d", attempt)
+}
+
+func (o *recordingObserver) OnAttemptEnd(info AttemptInfo) {
+	o.record("end %d %v %s", info.Attempt, info.Err, info.StopReason)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.infos = append(o.infos, info)
+}
+
+func (o *recordingObserver) OnWait(attempt uint, delay time.Duration) {
+	o.record("wait %d %s", attempt, delay)
+}
+
+func (o *recordingObserver) OnGiveUp(reason StopReason, err error) {
+	o.record("give up %s", reason)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.reason = reason
+	o.lastErr = err
+}
+
+func (o *recordingObserver) OnSuccess(attempt uint) {
+	o.record("success %d", attempt)
+}
+
+func TestObserver(t *testing.T) {
+	t.Run("attempts exhausted", func(t *testing.T) {
+		observer := &recordingObserver{}
+		var retrySum uint
+		err := New(
+			Attempts(3),
+			Delay(time.Millisecond),
+			DelayType(FixedDelay),
+			WithObserver(observer),
+			OnRetry(func(n uint, err error) { retrySum += n + 1 }),
+		).Do(
+			func() error { return errors.New("test") },
+		)
+		assert.Error(t, err)
+		assert.Equal(t, []string{
+			"start 1",
+			"end 1 test none",
+			"wait 2 1ms",
+			"start 2",
+			"end 2 test none",
+			"wait 3 1ms",
+			"start 3",
+			"end 3 test attempts_exhausted",
+			"give up attempts_exhausted",
+		}, observer.events)
+		assert.Equal(t, err, observer.lastErr)
+		assert.Equal(t, uint(6), retrySum, "OnRetry works together with observers")
+
+		for _, info := range observer.infos {
+			assert.False(t, info.Start.IsZero())
+			assert.Equal(t, info.End.Sub(info.Start), info.Duration)
+		}
+		assert.Equal(t, time.Millisecond, observer.infos[0].NextDelay)
+		assert.Equal(t, time.Duration(0), observer.infos[2].NextDelay)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		observer := &recordingObserver{}
+		attempts := 0
+		_, err := NewWithData[int](
+			Attempts(0),
+			Delay(time.Millisecond),
+			DelayType(FixedDelay),
+			WithObserver(observer),
+		).Do(
+			func() (int, error) {
+				attempts++
+				if attempts == 1 {
+					return 0, errors.New("test")
+				}
+				return 1, nil
+			},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"start 1",
+			"end 1 test none",
+			"wait 2 1ms",
+			"start 2",
+			"end 2 <nil> success",
+			"success 2",
+		}, observer.events)
+	})
+
+	testErr := errors.New("test")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reasons := []struct {
+		name     string
+		opts     []Option
+		err      error
+		expected StopReason
+	}{
+		{"unrecoverable", nil, Unrecoverable(testErr), StopReasonUnrecoverable},
+		{"unrecoverable infinite", []Option{Attempts(0)}, Unrecoverable(testErr), StopReasonUnrecoverable},
+		{"retry if", []Option{RetryIf(func(error) bool { return false })}, testErr, StopReasonRetryIf},
+		{"attempts for error", []Option{AttemptsForError(2, os.ErrInvalid)}, os.ErrInvalid, StopReasonAttemptsForErrorExhausted},
+		{"context", []Option{Context(ctx)}, testErr, StopReasonContext},
+		{"circuit open", []Option{WithCircuitBreaker(NewCircuitBreaker(BreakerConsecutiveFailures(1)))}, testErr, StopReasonCircuitOpen},
+	}
+	for _, tc := range reasons {
+		t.Run(tc.name, func(t *testing.T) {
+			observer := &recordingObserver{}
+			opts := append([]Option{Delay(time.Nanosecond), WithObserver(observer)}, tc.opts...)
+			err := New(opts...).Do(
+				func() error { return tc.err },
+			)
+			assert.Error(t, err)
+			assert.Equal(t, tc.expected, observer.reason)
+			assert.Equal(t, err, observer.lastErr)
+		})
+	}
+}
+
+func TestStopReasonString(t *testing.T) {
+	assert.Equal(t, "attempts_exhausted", StopReasonAttemptsExhausted.String())
+	assert.Equal(t, "unknown", StopReason(-1).String())
+}
@@ -38,14 +38,33 @@ type retrierCore struct {
 	maxDelay                      time.Duration
 	maxJitter                     time.Duration
 	onRetry                       OnRetryFunc
+	observers                     []Observer
+	tracer                        Tracer
 	retryIf                       RetryIfFunc
+	retryIfResult                 func(any, error) bool
 	delayType                     DelayTypeFunc
 	lastErrorOnly                 bool
 	context                       context.Context
 	timer                         Timer
 	wrapContextErrorWithLastError bool
-
-	maxBackOffN uint // pre-computed for BackOffDelay, immutable after New()
+	attemptTimeout                time.Duration
+	circuitBreaker                *CircuitBreaker
+	budget                        *RetryBudget
+	hedgeMaxParallel              uint
+	hedgeDelay                    time.Duration
+	maxElapsedTime                time.Duration
+	stopPolicy                    StopPolicy
+	errorHistoryLimit             uint
+	stopBeforeDeadline            bool
+	attemptEstimate               func(last time.Duration) time.Duration
+	classifier                    *Classifier
+
+	maxBackOffN uint       // pre-computed for BackOffDelay, immutable after New()
+	notify      []Observer // observers including the OnRetry adapter, immutable after New()
+
+	stopCounters []*stopAfterAttemptsForError // StopAfterAttemptsForError policies of stopPolicy, immutable after New()
+	historyLimit uint                         // limit of the error log of a retry sequence, immutable after New()
+	recordLimit  uint                         // limit of the AttemptRecords of a retry sequence, immutable after New()
 }
 
 // Delay implements DelayContext
@@ -87,27 +106,69 @@ func newRetrieerCore(opts ...Option) *retrierCore {
 		attemptsForError: make(map[error]uint),
 		delay:            100 * time.Millisecond,
 		maxJitter:        100 * time.Millisecond,
-		onRetry:          func(n uint, err error) {},
 		retryIf:          IsRecoverable,
 		delayType:        CombineDelay(BackOffDelay, RandomDelay),
 		lastErrorOnly:    false,
 		context:          context.Background(),
 		timer:            &timerImpl{},
+		attemptEstimate:  LastAttemptDuration,
 	}
 
 	for _, opt := range opts {
 		opt(core)
 	}
+	core.finalize()
+
+	return core
+}
+
+// finalize computes the derived fields after options were applied
+func (r *retrierCore) finalize() {
+	r.notify = r.observers
+	if r.onRetry != nil {
+		r.notify = append([]Observer{onRetryObserver{onRetry: r.onRetry}}, r.observers...)
+	}
+
+	r.stopCounters = stopCounters(r.stopPolicy, nil)
+
+	// the error log of UntilSucceeded is bounded by default, so it doesn't grow without limit;
+	// the records are bounded like the error log, and by default even if the error log is not
+	const defaultHistoryLimit uint = 100
+	r.historyLimit = r.errorHistoryLimit
+	if r.historyLimit == 0 && r.attempts == 0 {
+		r.historyLimit = defaultHistoryLimit
+	}
+	r.recordLimit = r.historyLimit
+	if r.recordLimit == 0 {
+		r.recordLimit = defaultHistoryLimit
+	}
 
 	const maxBackOffN uint = 62
-	core.maxBackOffN = maxBackOffN
-	if core.delay < 0 {
-		core.delay = 0
-	} else if core.delay > 0 {
-		core.maxBackOffN = maxBackOffN - uint(math.Floor(math.Log2(float64(core.delay))))
+	r.maxBackOffN = maxBackOffN
+	if r.delay < 0 {
+		r.delay = 0
+	} else if r.delay > 0 {
+		r.maxBackOffN = maxBackOffN - uint(math.Floor(math.Log2(float64(r.delay))))
 	}
+}
 
-	return core
+// with returns a copy of the core with `opts` applied
+func (r *retrierCore) with(opts ...Option) *retrierCore {
+	core := *r
+
+	core.attemptsForError = make(map[error]uint, len(r.attemptsForError))
+	for err, attempts := range r.attemptsForError {
+		core.attemptsForError[err] = attempts
+	}
+	// full slice expression, so WithObserver on the copy doesn't write to the shared array
+	core.observers = r.observers[:len(r.observers):len(r.observers)]
+
+	for _, opt := range opts {
+		opt(&core)
+	}
+	core.finalize()
+
+	return &core
 }
 
 // New creates a new Retrier with the given options.
@@ -116,6 +177,56 @@ func New(opts ...Option) *Retrier {
 	return &Retrier{retrierCore: newRetrieerCore(opts...)}
 }
 
+// With returns a copy of the Retrier with `opts` applied on top of its configuration,
+// the Retrier itself is not modified. It is cheap enough to be used per call:
+//
+//	users := retry.New(retry.Attempts(5), retry.Delay(time.Second))
+//	...
+//	err := users.With(retry.Attempts(2), retry.OnRetry(logRetry)).Do(fetchUser)
+//
+// The copy shares the CircuitBreaker, RetryBudget, Timer and observers of the Retrier,
+// WithObserver and WithMetrics add observers to the copy only.
+func (r *Retrier) With(opts ...Option) *Retrier {
+	return &Retrier{retrierCore: r.retrierCore.with(opts...)}
+}
+
+// With returns a copy of the RetrierWithData with `opts` applied on top of its configuration.
+// See Retrier.With for details.
+func (r *RetrierWithData[T]) With(opts ...Option) *RetrierWithData[T] {
+	return &RetrierWithData[T]{retrierCore: r.retrierCore.with(opts...)}
+}
+
+// RetryIfResult returns a copy of the RetrierWithData which decides whether a retry should be attempted
+// based on both the value and the error returned by the retried function, e.g. to retry successful calls
+// with a "pending" payload. `retryIfResult` is called after every attempt and must return true to retry:
+//   - a successful attempt rejected by `retryIfResult` is recorded as ErrUnsatisfactoryResult
+//   - a failed attempt is retried only when both RetryIf and `retryIfResult` allow it
+//
+// When attempts run out on a rejected value, the value is returned together with the error.
+// With keeps `retryIfResult`, nil removes it.
+//
+//	status, err := retry.NewWithData[string](
+//		retry.Attempts(5),
+//	).RetryIfResult(func(status string, err error) bool {
+//		return err != nil || status == "pending"
+//	}).Do(
+//		func() (string, error) { ... },
+//	)
+//	if errors.Is(err, retry.ErrUnsatisfactoryResult) {
+//		// status is still "pending"
+//	}
+func (r *RetrierWithData[T]) RetryIfResult(retryIfResult func(T, error) bool) *RetrierWithData[T] {
+	core := r.retrierCore.with()
+	core.retryIfResult = nil
+	if retryIfResult != nil {
+		core.retryIfResult = func(v any, err error) bool {
+			t, _ := v.(T)
+			return retryIfResult(t, err)
+		}
+	}
+	return &RetrierWithData[T]{retrierCore: core}
+}
+
 // NewWithData creates a new RetrierWithData[T] with the given options.
 // The returned retrier can be safely reused across multiple retry operations.
 func NewWithData[T any](opts ...Option) *RetrierWithData[T] {
@@ -124,7 +235,9 @@ func NewWithData[T any](opts ...Option) *RetrierWithData[T] {
 
 func emptyOption(r *retrierCore) {}
 
-// return the direct last error that came from the retried function
+// return the last error that came from the retried function (or the context cause) instead of the Error
+// with all errors; like the Error it is wrapped in a StopError, so match it with errors.Is or errors.As
+// instead of == or a type switch
 // default is false (return wrapped errors with everything)
 func LastErrorOnly(lastErrorOnly bool) Option {
 	return func(r *retrierCore) {
@@ -132,7 +245,20 @@ func LastErrorOnly(lastErrorOnly bool) Option {
 	}
 }
 
-// Attempts set count of retry. Setting to 0 will retry until the retried function succeeds.
+// ErrorHistoryLimit limits the number of errors kept in the Error wrapped by the returned StopError (and in StopError.Attempts)
+// to `limit`: the first limit/2 errors and the most recent ones.
+// StopError.Attempts keeps at most 100 records even without a limit.
+// StopError.Error renders the elided errors as "... N more ...", errors.Is and errors.As still match them
+// (the types of all their errors and up to `limit` distinct sentinel errors are tracked).
+// default is 0 (no limit), 100 with Attempts(0)
+func ErrorHistoryLimit(limit uint) Option {
+	return func(r *retrierCore) {
+		r.errorHistoryLimit = limit
+	}
+}
+
+// Attempts set count of retry. Setting to 0 will retry until the retried function succeeds,
+// the error log then keeps 100 errors unless ErrorHistoryLimit sets another limit.
 // default is 10
 func Attempts(attempts uint) Option {
 	return func(r *retrierCore) {
@@ -262,7 +388,187 @@ func FullJitterBackoffDelay(n uint, err error, config DelayContext) time.Duratio
 	return time.Duration(jitter)
 }
 
+// ExponentialBackoff returns a DelayTypeFunc growing the delay by `multiplier` after every attempt
+// and randomizing it by `randomizationFactor`, comparable to cenkalti/backoff's ExponentialBackOff.
+// Formula: interval = min(cap, base * multiplier^(attempt-1));
+// sleep = random_between(interval * (1 - randomizationFactor), interval * (1 + randomizationFactor))
+// It uses config.Delay as the base delay and config.MaxDelay as the cap, the delay saturates instead of overflowing.
+// `multiplier` less than 1 is treated as 1, `randomizationFactor` is limited to [0, 1].
+//
+//	retry.DelayType(retry.ExponentialBackoff(1.5, 0.5))
+func ExponentialBackoff(multiplier float64, randomizationFactor float64) DelayTypeFunc {
+	if multiplier < 1 || math.IsNaN(multiplier) {
+		multiplier = 1
+	}
+	if randomizationFactor < 0 || math.IsNaN(randomizationFactor) {
+		randomizationFactor = 0
+	} else if randomizationFactor > 1 {
+		randomizationFactor = 1
+	}
+
+	return func(n uint, _ error, config DelayContext) time.Duration {
+		if n > 0 {
+			n--
+		}
+
+		limit := float64(math.MaxInt64)
+		if maxDelay := config.MaxDelay(); maxDelay > 0 {
+			limit = float64(maxDelay)
+		}
+
+		interval := float64(config.Delay()) * math.Pow(multiplier, float64(n))
+		if interval > limit {
+			interval = limit
+		}
+		if interval <= 0 {
+			return 0
+		}
+
+		if randomizationFactor > 0 {
+			delta := randomizationFactor * interval
+			interval = interval - delta + rand.Float64()*2*delta // #nosec G404 -- Using math/rand is acceptable for non-security critical jitter.
+		}
+
+		return saturatingDuration(interval, limit)
+	}
+}
+
+// LinearBackoffDelay returns a DelayTypeFunc increasing the delay by `step` after every attempt.
+// Formula: sleep = base + step * (attempt-1)
+// It uses config.Delay as the base delay, the delay saturates instead of overflowing.
+func LinearBackoffDelay(step time.Duration) DelayTypeFunc {
+	return func(n uint, _ error, config DelayContext) time.Duration {
+		if n > 0 {
+			n--
+		}
+		return saturatingAdd(config.Delay(), saturatingMul(step, uint64(n)))
+	}
+}
+
+// FibonacciBackoffDelay is a DelayTypeFunc growing the delay along the Fibonacci sequence.
+// Formula: sleep = base * fib(attempt), i.e. base, base, 2*base, 3*base, 5*base, ...
+// It uses config.Delay as the base delay, the delay saturates instead of overflowing.
+func FibonacciBackoffDelay(n uint, _ error, config DelayContext) time.Duration {
+	base := config.Delay()
+	if base <= 0 {
+		return 0
+	}
+
+	var previous, current uint64 = 0, 1
+	for i := uint(1); i < n; i++ {
+		previous, current = current, previous+current
+		if current > uint64(math.MaxInt64/base) {
+			return math.MaxInt64
+		}
+	}
+
+	return saturatingMul(base, current)
+}
+
+// PolynomialBackoffDelay returns a DelayTypeFunc growing the delay polynomially with the attempt number.
+// Formula: sleep = base * attempt^exponent, e.g. exponent 2 gives base, 4*base, 9*base, ...
+// It uses config.Delay as the base delay, the delay saturates instead of overflowing.
+// Negative `exponent` is treated as 0.
+func PolynomialBackoffDelay(exponent float64) DelayTypeFunc {
+	if exponent < 0 || math.IsNaN(exponent) {
+		exponent = 0
+	}
+
+	return func(n uint, _ error, config DelayContext) time.Duration {
+		if n == 0 {
+			n = 1
+		}
+		return saturatingDuration(float64(config.Delay())*math.Pow(float64(n), exponent), float64(math.MaxInt64))
+	}
+}
+
+// saturatingAdd returns a + b, limited to math.MaxInt64 for non-negative `a` and `b`
+func saturatingAdd(a, b time.Duration) time.Duration {
+	if a > 0 && b > math.MaxInt64-a {
+		return math.MaxInt64
+	}
+	return a + b
+}
+
+// saturatingMul returns d * k, limited to math.MaxInt64 for non-negative `d`
+func saturatingMul(d time.Duration, k uint64) time.Duration {
+	if d <= 0 || k == 0 {
+		return 0
+	}
+	if k > uint64(math.MaxInt64/d) {
+		return math.MaxInt64
+	}
+	return d * time.Duration(k)
+}
+
+// saturatingDuration converts `d` nanoseconds to time.Duration, limited to `limit` and math.MaxInt64
+func saturatingDuration(d, limit float64) time.Duration {
+	if d >= limit {
+		d = limit
+	}
+	if d >= float64(math.MaxInt64) {
+		return math.MaxInt64
+	}
+	return time.Duration(d)
+}
+
+// EqualJitterBackoffDelay is a DelayTypeFunc that calculates delay using exponential backoff
+// with equal jitter. Half of the delay is the backoff ceiling, the other half is random.
+// Formula: temp = min(cap, base * 2^(attempt-1)); sleep = temp/2 + random_between(0, temp/2)
+// It uses config.Delay as the base delay, config.MaxDelay as the cap and
+// config.MaxBackOffN as the maximum exponent, same as BackOffDelay.
+func EqualJitterBackoffDelay(n uint, err error, config DelayContext) time.Duration {
+	ceiling := BackOffDelay(n, err, config)
+	if maxDelay := config.MaxDelay(); maxDelay > 0 && ceiling > maxDelay {
+		ceiling = maxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	half := ceiling / 2
+	return half + time.Duration(rand.Int63n(int64(ceiling-half)+1)) // #nosec G404 -- Using math/rand is acceptable for non-security critical jitter.
+}
+
+// DecorrelatedJitterDelay is a DelayTypeFunc implementing the AWS "decorrelated jitter" backoff.
+// Every delay is random between the base delay and three times the previous delay.
+// Formula: sleep = min(cap, random_between(base, previous_sleep * 3))
+// It uses config.Delay as the base delay, the previous delay of the retry operation as the previous sleep
+// (the base delay before the first retry or when config doesn't provide a PreviousDelay() time.Duration method)
+// and config.MaxDelay as the cap.
+// The growth is also limited by config.MaxBackOffN, i.e. it never exceeds the largest BackOffDelay.
+func DecorrelatedJitterDelay(_ uint, _ error, config DelayContext) time.Duration {
+	base := config.Delay()
+	if base <= 0 {
+		return 0
+	}
+
+	ceiling := base << config.MaxBackOffN()
+	if maxDelay := config.MaxDelay(); maxDelay > 0 && ceiling > maxDelay {
+		ceiling = maxDelay
+	}
+	if ceiling <= base {
+		return ceiling
+	}
+
+	var previous time.Duration
+	if previousDelay, ok := config.(interface{ PreviousDelay() time.Duration }); ok {
+		previous = previousDelay.PreviousDelay()
+	}
+	if previous < base {
+		previous = base
+	}
+	upper := ceiling
+	if previous < ceiling/3 {
+		upper = previous * 3
+	}
+
+	return base + time.Duration(rand.Int63n(int64(upper-base)+1)) // #nosec G404 -- Using math/rand is acceptable for non-security critical jitter.
+}
+
 // OnRetry function callback are called each retry
+// It is an adapter registering an Observer which calls `onRetry` for every failed attempt
+// that passed RetryIf.
 //
 // log each retry example:
 //
@@ -283,6 +589,57 @@ func OnRetry(onRetry OnRetryFunc) Option {
 	}
 }
 
+// WithObserver registers an Observer notified about every attempt, wait and the outcome of the retry.
+// May be used multiple times to register more observers.
+//
+//	type logObserver struct {
+//		retry.NopObserver
+//	}
+//
+//	func (logObserver) OnAttemptEnd(info retry.AttemptInfo) {
+//		if info.Err != nil {
+//			log.Printf("attempt #%d failed after %s: %s", info.Attempt, info.Duration, info.Err)
+//		}
+//	}
+//
+//	retry.New(
+//		retry.WithObserver(logObserver{}),
+//	).Do(
+//		func() error { ... },
+//	)
+func WithObserver(observer Observer) Option {
+	if observer == nil {
+		return emptyOption
+	}
+	return func(r *retrierCore) {
+		r.observers = append(r.observers, observer)
+	}
+}
+
+// WithMetrics registers Metrics counting attempts, retries, successes and give-ups (by StopReason)
+// and observing delays between attempts.
+// does not apply by default
+//
+// see package github.com/avast/retry-go/v5/retryprometheus for a Prometheus implementation
+func WithMetrics(metrics Metrics) Option {
+	if metrics == nil {
+		return emptyOption
+	}
+	return WithObserver(metricsObserver{metrics: metrics})
+}
+
+// WithTracer sets a Tracer starting a span for every retry sequence.
+// Every finished attempt is recorded as a span event with the attempt number, error and delay.
+// Functions executed by DoCtx receive the context of the span.
+// does not apply by default
+//
+// see package github.com/avast/retry-go/v5/retryotel for an OpenTelemetry implementation
+func WithTracer(tracer Tracer) Option {
+	return func(r *retrierCore) {
+		r.tracer = tracer
+	}
+}
+
 // RetryIf controls whether a retry should be attempted after an error
 // (assuming there are any retry attempts remaining)
 //
@@ -320,6 +677,8 @@ func RetryIf(retryIf RetryIfFunc) Option {
 // Context allow to set context of retry
 // default are Background context
 //
+// The context is shared by all calls of the retrier, use Retrier.DoContext for request-scoped contexts.
+//
 // example of immediately cancellation (maybe it isn't the best example, but it describes behavior enough; I hope)
 //
 //	ctx, cancel := context.WithCancel(context.Background())
@@ -360,9 +719,171 @@ func WithTimer(t Timer) Option {
 	}
 }
 
+// AttemptTimeout sets the maximum duration of a single attempt.
+// Each attempt runs with a child of the retry context which is cancelled after `attemptTimeout`;
+// the context is passed to functions executed by DoCtx.
+// An attempt which fails after its context timed out is recorded as an error matching
+// errors.Is(err, ErrAttemptTimeout) and is retried like any other error.
+// does not apply by default
+//
+//	retry.New(
+//		retry.AttemptTimeout(time.Second),
+//	).DoCtx(
+//		func(ctx context.Context) error {
+//			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+//			...
+//		},
+//	)
+func AttemptTimeout(attemptTimeout time.Duration) Option {
+	return func(r *retrierCore) {
+		r.attemptTimeout = attemptTimeout
+	}
+}
+
+// MaxElapsedTime stops retrying once the next delay would push the total time
+// since the start of the retry operation past `maxElapsedTime`.
+// The returned error records ErrMaxElapsedTime after the errors of the attempts.
+// The time is measured by the Timer when it has a `Now() time.Time` method (e.g. retrytest.FakeClock),
+// otherwise by the wall clock.
+// default is 0 (no limit)
+func MaxElapsedTime(maxElapsedTime time.Duration) Option {
+	return func(r *retrierCore) {
+		r.maxElapsedTime = maxElapsedTime
+	}
+}
+
+// StopBeforeDeadline gives up immediately, instead of waiting for the context to expire,
+// when the next delay plus the estimated duration of the next attempt (see AttemptEstimate)
+// would not fit before the deadline of the context.
+// The time left is measured by the wall clock like the deadline, the duration of the attempts
+// by the Timer when it has a `Now() time.Time` method, like MaxElapsedTime.
+// The returned error records ErrWouldExceedDeadline after the errors of the attempts,
+// so the real failure is reported instead of the context error.
+// default is false
+func StopBeforeDeadline(stopBeforeDeadline bool) Option {
+	return func(r *retrierCore) {
+		r.stopBeforeDeadline = stopBeforeDeadline
+	}
+}
+
+// AttemptEstimate sets how StopBeforeDeadline estimates the duration of the next attempt
+// from the duration `last` of the failed one, nil doesn't estimate it: only the delay must fit.
+//
+//	// the next attempt may take up to the attempt timeout
+//	retry.AttemptEstimate(func(time.Duration) time.Duration { return 5 * time.Second })
+//
+// default is LastAttemptDuration
+func AttemptEstimate(estimate func(last time.Duration) time.Duration) Option {
+	return func(r *retrierCore) {
+		r.attemptEstimate = estimate
+	}
+}
+
+// LastAttemptDuration estimates the duration of the next attempt by the duration `last` of the failed one,
+// see AttemptEstimate
+func LastAttemptDuration(last time.Duration) time.Duration {
+	return last
+}
+
+// Stop sets a StopPolicy consulted after every failed attempt which would be retried otherwise.
+// It complements Attempts, which still applies (use UntilSucceeded to leave stopping to the policy).
+// The reason reported by the policy is passed to observers, StopReasonMaxElapsedTime
+// additionally records ErrMaxElapsedTime in the returned error.
+//
+//	retry.New(
+//		retry.UntilSucceeded(),
+//		retry.Stop(retry.StopAny(
+//			retry.StopAfterAttempts(5),
+//			retry.StopAfterDelay(30*time.Second),
+//			retry.StopAfterAttemptsForError(2, ErrQuota),
+//		)),
+//	)
+func Stop(policy StopPolicy) Option {
+	if policy == nil {
+		return emptyOption
+	}
+	return func(r *retrierCore) {
+		r.stopPolicy = policy
+	}
+}
+
+// WithClassifier sets a Classifier deciding the errors of failed attempts.
+// Errors matched by its rules are decided by the Classifier only,
+// other errors are still decided by RetryIf and AttemptsForError.
+// does not apply by default
+//
+//	retry.New(
+//		retry.UntilSucceeded(),
+//		retry.MaxElapsedTime(time.Minute),
+//		retry.WithClassifier(retry.NewClassifier(retry.TransientErrors()...).With(
+//			retry.OnAnyError(retry.GiveUp),
+//		)),
+//	)
+func WithClassifier(classifier *Classifier) Option {
+	return func(r *retrierCore) {
+		r.classifier = classifier
+	}
+}
+
+// WithCircuitBreaker attaches a circuit breaker to the retrier.
+// Every attempt is reported to the breaker and while the breaker is open the retried function
+// is not called; the retry stops immediately with ErrCircuitOpen instead.
+// The same CircuitBreaker may be shared by many retriers.
+// does not apply by default
+//
+//	cb := retry.NewCircuitBreaker(retry.BreakerConsecutiveFailures(3))
+//
+//	retry.New(
+//		retry.WithCircuitBreaker(cb),
+//	).Do(
+//		func() error { ... },
+//	)
+func WithCircuitBreaker(cb *CircuitBreaker) Option {
+	return func(r *retrierCore) {
+		r.circuitBreaker = cb
+	}
+}
+
+// Budget attaches a retry budget to the retrier.
+// Successful first attempts deposit to the budget and every retry withdraws from it.
+// When the budget is exhausted the retry stops and the returned error wraps both the last error
+// and ErrBudgetExhausted.
+// The same RetryBudget may be shared by many retriers.
+// does not apply by default
+//
+//	budget := retry.NewRetryBudget(retry.BudgetRatio(0.2))
+//
+//	retry.New(
+//		retry.Budget(budget),
+//	).Do(
+//		func() error { ... },
+//	)
+func Budget(budget *RetryBudget) Option {
+	return func(r *retrierCore) {
+		r.budget = budget
+	}
+}
+
+// Hedging configures hedged execution used by Hedge methods.
+// Another copy of the call is started when no copy has returned within `hedgeDelay`,
+// with at most `maxParallel` copies in flight.
+// default is a single copy (no hedging)
+//
+//	body, err := retry.NewWithData[[]byte](
+//		retry.Hedging(3, 50*time.Millisecond),
+//	).Hedge(
+//		func(ctx context.Context) ([]byte, error) { ... },
+//	)
+func Hedging(maxParallel uint, hedgeDelay time.Duration) Option {
+	return func(r *retrierCore) {
+		r.hedgeMaxParallel = maxParallel
+		r.hedgeDelay = hedgeDelay
+	}
+}
+
 // WrapContextErrorWithLastError allows the context error to be returned wrapped with the last error that the
-// retried function returned. This is only applicable when Attempts is set to 0 to retry indefinitly and when
-// using a context to cancel / timeout
+// retried function returned, when using a context to cancel / timeout together with LastErrorOnly.
+// Without LastErrorOnly the Error wrapped by the returned StopError holds all errors (ending with the context error) anyway.
 //
 // default is false
 //
@@ -375,6 +896,7 @@ func WithTimer(t Timer) Option {
 //		},
 //		retry.Context(ctx),
 //		retry.Attempts(0),
+//		retry.LastErrorOnly(true),
 //		retry.WrapContextErrorWithLastError(true),
 //	)
 func WrapContextErrorWithLastError(wrapContextErrorWithLastError bool) Option {
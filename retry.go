@@ -80,6 +80,16 @@ Reusable retrier for high-frequency retry operations:
 		}
 	}
 
+Request-scoped context with a reusable retrier:
+
+	// The context is passed per call, both to the retried function and for cancelling the retries
+	err := retrier.DoContext(ctx,
+		func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			...
+		},
+	)
+
 [More examples](https://github.com/avast/retry-go/tree/main/examples)
 
 # SEE ALSO
@@ -108,6 +118,10 @@ Reusable retrier for high-frequency retry operations:
   - This change improves performance, simplifies the API, and provides a cleaner interface
   - `Unwrap()` now returns `[]error` instead of `error` to support Go 1.20 multiple error wrapping.
   - `errors.Unwrap(err)` will now return `nil` (same as `errors.Join`). Use `errors.Is` or `errors.As` to inspect wrapped errors.
+  - `Attempts(0)` (`UntilSucceeded`) behaves like other attempt counts: it returns an `Error` holding the errors of the attempts, instead of the bare error, when `RetryIf` returns false or the error is `Unrecoverable`
+  - `Attempts(0)` keeps an error log of the attempts, bounded to 100 errors by default (see `ErrorHistoryLimit`)
+  - `WrapContextErrorWithLastError` is ignored unless `LastErrorOnly` is set, the returned `Error` ends with the context error anyway
+  - Retry operations which stop without success return a `*StopError` describing why they stopped. It wraps the `Error`, or the last error or context cause with `LastErrorOnly`: use `errors.As(err, &retryErr)` instead of `err.(retry.Error)` and `errors.Is` instead of comparing errors with `==`.
 
 * 4.0.0
   - infinity retry is possible by set `Attempts(0)` by PR [#49](https://github.com/avast/retry-go/pull/49)
@@ -130,6 +144,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 )
@@ -140,6 +155,12 @@ type RetryableFunc func() error
 // Function signature of retryable function with data
 type RetryableFuncWithData[T any] func() (T, error)
 
+// Function signature of retryable function receiving the attempt context
+type RetryableFuncWithContext func(ctx context.Context) error
+
+// Function signature of retryable function with data receiving the attempt context
+type RetryableFuncWithDataAndContext[T any] func(ctx context.Context) (T, error)
+
 // Default r.timer is a wrapper around time.After
 type timerImpl struct{}
 
@@ -147,113 +168,298 @@ func (t *timerImpl) After(d time.Duration) <-chan time.Time {
 	return time.After(d)
 }
 
+// contextTimer is implemented by Timers which drop the waits abandoned once `ctx` is done,
+// e.g. retrytest.FakeClock
+type contextTimer interface {
+	AfterContext(ctx context.Context, d time.Duration) <-chan time.Time
+}
+
+// after waits `d` using the Timer, `ctx` is the context abandoning the wait when done
+func (r *retrierCore) after(ctx context.Context, d time.Duration) <-chan time.Time {
+	if timer, ok := r.timer.(contextTimer); ok {
+		return timer.AfterContext(ctx, d)
+	}
+	return r.timer.After(d)
+}
+
 // Do executes the retryable function using this Retrier's configuration.
 func (r *Retrier) Do(retryableFunc RetryableFunc) error {
-	retryableFuncWithData := func() (any, error) {
+	retryableFuncWithData := func(context.Context) (any, error) {
 		return nil, retryableFunc()
 	}
 
-	_, err := doWithData(r.retrierCore, retryableFuncWithData)
+	_, err := doWithData(r.retrierCore, r.context, retryableFuncWithData)
+	return err
+}
+
+// DoWithContext executes the retryable function using this Retrier's configuration,
+// with `ctx` instead of the context set by the Context option.
+func (r *Retrier) DoWithContext(ctx context.Context, retryableFunc RetryableFunc) error {
+	retryableFuncWithData := func(context.Context) (any, error) {
+		return nil, retryableFunc()
+	}
+
+	_, err := doWithData(r.retrierCore, ctx, retryableFuncWithData)
+	return err
+}
+
+// DoContext executes the retryable function using this Retrier's configuration,
+// with the request-scoped `ctx` instead of the context set by the Context option.
+// `ctx` cancels the retries and each attempt receives a context derived from it
+// which is cancelled after AttemptTimeout (if set).
+func (r *Retrier) DoContext(ctx context.Context, retryableFunc RetryableFuncWithContext) error {
+	retryableFuncWithData := func(ctx context.Context) (any, error) {
+		return nil, retryableFunc(ctx)
+	}
+
+	_, err := doWithData(r.retrierCore, ctx, retryableFuncWithData)
+	return err
+}
+
+// DoCtx executes the retryable function using this Retrier's configuration.
+// Each attempt receives a context derived from the Retrier's context which is
+// cancelled after AttemptTimeout (if set).
+func (r *Retrier) DoCtx(retryableFunc RetryableFuncWithContext) error {
+	retryableFuncWithData := func(ctx context.Context) (any, error) {
+		return nil, retryableFunc(ctx)
+	}
+
+	_, err := doWithData(r.retrierCore, r.context, retryableFuncWithData)
 	return err
 }
 
 // Do executes the retryable function using this RetrierWithData's configuration.
 func (r *RetrierWithData[T]) Do(retryableFunc RetryableFuncWithData[T]) (T, error) {
-	return doWithData(r.retrierCore, retryableFunc)
+	return doWithData(r.retrierCore, r.context, func(context.Context) (T, error) {
+		return retryableFunc()
+	})
+}
+
+// DoWithContext executes the retryable function using this RetrierWithData's configuration,
+// with `ctx` instead of the context set by the Context option.
+func (r *RetrierWithData[T]) DoWithContext(ctx context.Context, retryableFunc RetryableFuncWithData[T]) (T, error) {
+	return doWithData(r.retrierCore, ctx, func(context.Context) (T, error) {
+		return retryableFunc()
+	})
 }
 
-func doWithData[T any](r *retrierCore, retryableFunc RetryableFuncWithData[T]) (T, error) {
+// DoContext executes the retryable function using this RetrierWithData's configuration,
+// with the request-scoped `ctx` instead of the context set by the Context option.
+// See Retrier.DoContext for details.
+func (r *RetrierWithData[T]) DoContext(ctx context.Context, retryableFunc RetryableFuncWithDataAndContext[T]) (T, error) {
+	return doWithData(r.retrierCore, ctx, retryableFunc)
+}
+
+// DoCtx executes the retryable function using this RetrierWithData's configuration.
+// Each attempt receives a context derived from the retrier's context which is
+// cancelled after AttemptTimeout (if set).
+func (r *RetrierWithData[T]) DoCtx(retryableFunc RetryableFuncWithDataAndContext[T]) (T, error) {
+	return doWithData(r.retrierCore, r.context, retryableFunc)
+}
+
+// runAttempt calls the retryable function once unless the circuit breaker is open
+// and reports the outcome to the circuit breaker
+func runAttempt[T any](r *retrierCore, ctx context.Context, retryableFunc RetryableFuncWithDataAndContext[T]) (T, error) {
+	if r.circuitBreaker == nil {
+		return runAttemptWithTimeout(r, ctx, retryableFunc)
+	}
+
+	generation, err := r.circuitBreaker.allow()
+	if err != nil {
+		var emptyT T
+		return emptyT, err
+	}
+
+	returned := false
+	defer func() {
+		// the retried function panicked, count it as failed so a half-open probe slot is not leaked
+		if !returned {
+			r.circuitBreaker.record(generation, false)
+		}
+	}()
+
+	t, err := runAttemptWithTimeout(r, ctx, retryableFunc)
+	returned = true
+	if err != nil && ctx.Err() != nil {
+		// cancelled by the caller, which says nothing about the health of the dependency
+		r.circuitBreaker.release(generation)
+		return t, err
+	}
+	r.circuitBreaker.record(generation, err == nil)
+	return t, err
+}
+
+// runAttemptWithTimeout calls the retryable function once, bounding it by AttemptTimeout when set
+func runAttemptWithTimeout[T any](r *retrierCore, ctx context.Context, retryableFunc RetryableFuncWithDataAndContext[T]) (T, error) {
+	if r.attemptTimeout <= 0 {
+		return retryableFunc(ctx)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, r.attemptTimeout)
+	defer cancel()
+
+	t, err := retryableFunc(attemptCtx)
+	if err != nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		err = attemptTimeoutError{err}
+	}
+	return t, err
+}
+
+func doWithData[T any](r *retrierCore, ctx context.Context, retryableFunc RetryableFuncWithDataAndContext[T]) (T, error) {
 	var emptyT T
 	var n uint
 
-	if err := context.Cause(r.context); err != nil {
+	s := newRetryState(r, ctx)
+
+	if err := context.Cause(s.ctx); err != nil {
+		err = s.giveUp(StopReasonContext, err)
 		return emptyT, err
 	}
 
-	// Setting r.attempts to 0 means we'll retry until we succeed
-	var lastErr error
-	if r.attempts == 0 {
-		for {
-			t, err := retryableFunc()
-			if err == nil {
-				return t, nil
-			}
+	// result holds the value of the last attempt rejected by RetryIfResult
+	var result T
 
-			if !IsRecoverable(err) {
-				return emptyT, err
-			}
+	errorLog := Error{}
 
-			if !r.retryIf(err) {
-				return emptyT, err
+	// Setting r.attempts to 0 means we'll retry until we succeed
+	for {
+		attempt := n + 1
+		start := s.attemptStart(attempt)
+		t, err := runAttempt(r, s.ctx, retryableFunc)
+		result = emptyT
+		if err == nil {
+			if r.retryIfResult == nil || !r.retryIfResult(t, nil) {
+				s.attemptEnd(attempt, start, nil, 0, StopReasonSuccess)
+				if n == 0 && r.budget != nil {
+					r.budget.Deposit()
+				}
+				return t, nil
 			}
+			result, err = t, ErrUnsatisfactoryResult
+		}
 
-			lastErr = err
+		errorLog = s.appendError(errorLog, unpackUnrecoverable(err))
 
-			r.onRetry(n, err)
+		delay, reason := failureDecision(&s, attempt, start, t, err, false)
+		if reason == StopReasonNone {
 			n++
-			select {
-			case <-r.timer.After(r.computeDelay(n, err)):
-			case <-r.context.Done():
-				if r.wrapContextErrorWithLastError {
-					return emptyT, Error{context.Cause(r.context), lastErr}
-				}
-				return emptyT, context.Cause(r.context)
-			}
 		}
-	}
+		s.attemptEnd(attempt, start, err, delay, reason)
 
-	errorLog := Error{}
+		if reason != StopReasonNone {
+			err = s.giveUp(reason, s.failure(errorLog, reasonError(reason)))
+			return result, err
+		}
 
-	attemptsForErrorCopy := make(map[error]uint, len(r.attemptsForError))
-	for err, attempts := range r.attemptsForError {
-		attemptsForErrorCopy[err] = attempts
+		select {
+		case <-r.after(s.ctx, delay):
+		case <-s.ctx.Done():
+			err = s.giveUp(StopReasonContext, s.contextFailure(errorLog))
+			return result, err
+		}
 	}
+}
 
-shouldRetry:
-	for {
-		t, err := retryableFunc()
-		if err == nil {
-			return t, nil
+// failure returns the error of a retry sequence which stopped after the errors in `errorLog`,
+// `sentinel` (if not nil) is recorded after them
+func (s *retryState) failure(errorLog Error, sentinel error) error {
+	if !s.lastErrorOnly {
+		if sentinel != nil {
+			return append(errorLog, sentinel)
 		}
+		return errorLog
+	}
 
-		errorLog = append(errorLog, unpackUnrecoverable(err))
-
-		if !r.retryIf(err) {
-			break
-		}
+	lastErr := errorLog.LastError()
+	if sentinel != nil {
+		return Error{lastErr, sentinel}
+	}
+	return lastErr
+}
 
-		r.onRetry(n, err)
+// contextFailure returns the error of a retry sequence which stopped after the errors in `errorLog`
+// because the context was done
+func (s *retryState) contextFailure(errorLog Error) error {
+	cause := context.Cause(s.ctx)
+	switch {
+	case !s.lastErrorOnly:
+		return append(errorLog, cause)
+	case s.wrapContextErrorWithLastError && len(errorLog) > 0:
+		return Error{cause, errorLog.LastError()}
+	default:
+		return cause
+	}
+}
 
-		for errToCheck, attemptsForThisError := range attemptsForErrorCopy {
-			if errors.Is(err, errToCheck) {
-				attemptsForThisError--
-				attemptsForErrorCopy[errToCheck] = attemptsForThisError
-				if attemptsForThisError <= 0 {
-					break shouldRetry
-				}
-			}
+// failureDecision decides how the retry sequence goes on after a failed attempt (started at `start`)
+// which returned `t` and `err`, when `started` attempts have been started and, if `inFlight`,
+// others are still running (hedged copies). It returns why the retry sequence must stop,
+// otherwise the delay before the next attempt, which is computed only when no attempt is in flight.
+func failureDecision[T any](s *retryState, started uint, start time.Time, t T, err error, inFlight bool) (time.Duration, StopReason) {
+	s.countStopError(err)
+
+	reason, classified := s.classify(err)
+	if !classified {
+		reason = failureReason(s.retrierCore, t, err)
+		if reason == StopReasonNone {
+			reason = s.attemptsForErrorReason(err)
 		}
+	}
 
+	switch {
+	case reason != StopReasonNone || inFlight:
+		return 0, reason
+	case s.retrierCore.attempts > 0 && started >= s.retrierCore.attempts:
 		// if this is last attempt - don't wait
-		if n == r.attempts-1 {
-			break shouldRetry
+		return 0, StopReasonAttemptsExhausted
+	default:
+		return s.nextDelay(started, start, err)
+	}
+}
+
+// attemptsForErrorReason counts the failed attempt which returned `err` for AttemptsForError,
+// it returns StopReasonAttemptsForErrorExhausted if no attempt is left for the error
+func (s *retryState) attemptsForErrorReason(err error) StopReason {
+	if len(s.retrierCore.attemptsForError) == 0 {
+		return StopReasonNone
+	}
+	if s.attemptsForErrorLeft == nil {
+		s.attemptsForErrorLeft = make(map[error]uint, len(s.retrierCore.attemptsForError))
+		for err, attempts := range s.retrierCore.attemptsForError {
+			s.attemptsForErrorLeft[err] = attempts
 		}
-		n++
-		select {
-		case <-r.timer.After(r.computeDelay(n, err)):
-		case <-r.context.Done():
-			if r.lastErrorOnly {
-				return emptyT, context.Cause(r.context)
-			}
+	}
 
-			return emptyT, append(errorLog, context.Cause(r.context))
+	for errToCheck, attemptsForThisError := range s.attemptsForErrorLeft {
+		if errors.Is(err, errToCheck) {
+			attemptsForThisError--
+			s.attemptsForErrorLeft[errToCheck] = attemptsForThisError
+			if attemptsForThisError <= 0 {
+				return StopReasonAttemptsForErrorExhausted
+			}
 		}
 	}
+	return StopReasonNone
+}
 
-	if r.lastErrorOnly {
-		return emptyT, errorLog[len(errorLog)-1]
+// failureReason returns why the failed attempt which returned `t` and `err` must not be retried
+// or StopReasonNone if it may be retried
+func failureReason[T any](r *retrierCore, t T, err error) StopReason {
+	switch {
+	case err == ErrUnsatisfactoryResult:
+		return StopReasonNone
+	case errors.Is(err, ErrCircuitOpen):
+		return StopReasonCircuitOpen
+	case !r.retryIf(err):
+		if !IsRecoverable(err) {
+			return StopReasonUnrecoverable
+		}
+		return StopReasonRetryIf
+	case r.retryIfResult != nil && !r.retryIfResult(t, err):
+		return StopReasonRetryIf
+	default:
+		return StopReasonNone
 	}
-	return emptyT, errorLog
 }
 
 // Error type represents list of errors in retry
@@ -262,16 +468,206 @@ type Error []error
 // Error method return string representation of Error
 // It is an implementation of error interface
 func (e Error) Error() string {
-	logWithNumber := make([]string, len(e))
+	return e.format(0, 0)
+}
+
+// format renders the Error, `elided` errors elided by ErrorHistoryLimit
+// are rendered as "... N more ..." at index `elidedAt`
+func (e Error) format(elided uint, elidedAt int) string {
+	logWithNumber := make([]string, 0, len(e)+1)
 	for i, l := range e {
+		number := i + 1
+		if elided > 0 && i >= elidedAt {
+			if i == elidedAt {
+				logWithNumber = append(logWithNumber, fmt.Sprintf("... %d more ...", elided))
+			}
+			number += int(elided)
+		}
+
 		if l != nil {
-			logWithNumber[i] = fmt.Sprintf("#%d: %s", i+1, l.Error())
+			logWithNumber = append(logWithNumber, fmt.Sprintf("#%d: %s", number, l.Error()))
+		} else {
+			logWithNumber = append(logWithNumber, "")
 		}
 	}
 
 	return fmt.Sprintf("All attempts fail:\n%s", strings.Join(logWithNumber, "\n"))
 }
 
+// StopError is the error returned by a retry operation which stopped without success.
+// It wraps the Error holding the errors of the attempts (or, with LastErrorOnly, the last error
+// or the context cause) and describes why and after how many attempts the retry operation stopped.
+//
+//	err := retrier.Do(fetch)
+//	var stopErr *retry.StopError
+//	if errors.As(err, &stopErr) {
+//		log.Printf("gave up after %d attempts: %s", stopErr.AttemptCount(), stopErr.StopReason())
+//	}
+//
+// errors.Is and errors.As see through a StopError, to the Error and to the errors elided by ErrorHistoryLimit.
+type StopError struct {
+	// errs holds the returned error followed by the errors elided by ErrorHistoryLimit
+	errs     []error
+	reason   StopReason
+	attempts uint
+	elapsed  time.Duration
+	records  []AttemptRecord
+
+	// number of errors elided by ErrorHistoryLimit and where they were elided
+	elided   uint
+	elidedAt int
+}
+
+// Error renders the wrapped error, errors elided by ErrorHistoryLimit are rendered as "... N more ..."
+func (e *StopError) Error() string {
+	if log, ok := e.errs[0].(Error); ok {
+		return log.format(e.elided, e.elidedAt)
+	}
+	return e.errs[0].Error()
+}
+
+// Err returns the wrapped error: the Error holding the errors of the attempts
+// or, with LastErrorOnly, the last error or the context cause
+func (e *StopError) Err() error {
+	return e.errs[0]
+}
+
+// StopReason returns why the retry operation stopped
+func (e *StopError) StopReason() StopReason {
+	return e.reason
+}
+
+// AttemptCount returns the number of attempts made by the retry operation
+func (e *StopError) AttemptCount() uint {
+	return e.attempts
+}
+
+// Elapsed returns the total time taken by the retry operation, including delays
+func (e *StopError) Elapsed() time.Duration {
+	return e.elapsed
+}
+
+// AttemptRecord describes a failed attempt of the retry operation which returned a StopError
+type AttemptRecord struct {
+	// Attempt is the number of the attempt, starting at 1
+	Attempt uint
+	// Start is the start time of the attempt
+	Start time.Time
+	// Duration is the time spent in the retried function
+	Duration time.Duration
+	// Delay is the delay before the next attempt, 0 if there was no next attempt
+	Delay time.Duration
+	// Err is the error returned by the attempt
+	Err error
+}
+
+// Attempts returns the records of the failed attempts of the retry operation.
+// Like the errors of the Error they are bounded by ErrorHistoryLimit, to 100 records
+// by default: the first half of them and the most recent ones.
+func (e *StopError) Attempts() []AttemptRecord {
+	return append([]AttemptRecord(nil), e.records...)
+}
+
+// Unwrap returns the wrapped error followed by the errors elided by ErrorHistoryLimit
+func (e *StopError) Unwrap() []error {
+	return e.errs
+}
+
+// WrappedErrors returns the same errors as Unwrap.
+// It is an implementation of the `errwrap.Wrapper` interface.
+func (e *StopError) WrappedErrors() []error {
+	return e.errs
+}
+
+// appendBounded appends `v` to `s` keeping at most `limit` elements (0 means no limit):
+// the first limit/2 elements and the most recent ones.
+// It returns the elided element and true if an element had to be elided.
+func appendBounded[T any](s []T, v T, limit uint) ([]T, T, bool) {
+	var elided T
+	if limit == 0 || uint(len(s)) < limit {
+		return append(s, v), elided, false
+	}
+
+	first := limit / 2
+	elided = s[first]
+	copy(s[first:], s[first+1:])
+	s[len(s)-1] = v
+	return s, elided, true
+}
+
+// appendError appends `err` to the error log, bounded by ErrorHistoryLimit
+func (s *retryState) appendError(errorLog Error, err error) Error {
+	errorLog, elided, ok := appendBounded(errorLog, err, s.historyLimit)
+	if !ok {
+		return errorLog
+	}
+
+	s.elided++
+	if s.trackElided(elided) {
+		s.elidedErrors = append(s.elidedErrors, elided)
+	}
+	return errorLog
+}
+
+// trackElided tracks what errors.Is and errors.As can match in the chain of the elided error `err`:
+// the types of its errors and its sentinels, the comparable errors which wrap nothing.
+// It returns true if `err` matches anything the elided errors kept so far don't, so it has to be kept.
+// Up to ErrorHistoryLimit distinct sentinels are tracked.
+func (s *retryState) trackElided(err error) bool {
+	if err == nil {
+		return false
+	}
+	if s.elidedKeys == nil {
+		s.elidedKeys = make(map[interface{}]struct{})
+	}
+
+	tracked := false
+	typ := reflect.TypeOf(err)
+	if _, seen := s.elidedKeys[typ]; !seen {
+		s.elidedKeys[typ] = struct{}{}
+		tracked = true
+	}
+
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return s.trackElided(e.Unwrap()) || tracked
+	case interface{ Unwrap() []error }:
+		for _, wrapped := range e.Unwrap() {
+			if s.trackElided(wrapped) {
+				tracked = true
+			}
+		}
+		return tracked
+	}
+
+	if s.elidedSentinels < s.historyLimit && reflect.ValueOf(err).Comparable() {
+		if _, seen := s.elidedKeys[err]; !seen {
+			s.elidedKeys[err] = struct{}{}
+			s.elidedSentinels++
+			tracked = true
+		}
+	}
+	return tracked
+}
+
+// ReasonOf returns why the retry operation which returned `err` stopped.
+// `err` may wrap the returned error. It returns StopReasonNone if `err` was not returned by a retrier.
+//
+//	err := retrier.Do(fetch)
+//	switch retry.ReasonOf(err) {
+//	case retry.StopReasonContext:
+//		// we were cancelled
+//	case retry.StopReasonAttemptsExhausted:
+//		// dependency down
+//	}
+func ReasonOf(err error) StopReason {
+	var e *StopError
+	if errors.As(err, &e) {
+		return e.reason
+	}
+	return StopReasonNone
+}
+
 func (e Error) Is(target error) bool {
 	for _, v := range e {
 		if errors.Is(v, target) {
@@ -318,7 +714,8 @@ func (e Error) As(target interface{}) bool {
 //
 // Example - Get the last error directly (for migration):
 //
-//	if retryErr, ok := err.(retry.Error); ok {
+//	var retryErr retry.Error
+//	if errors.As(err, &retryErr) {
 //		lastErr := retryErr.LastError()
 //	}
 //
@@ -350,7 +747,10 @@ func (e Error) WrappedErrors() []error {
 //	if errors.Is(retryErr, specificError) { ... }
 //
 //	// v5.0.0 code (option 2 - if you need the last error):
-//	lastErr := retryErr.(retry.Error).LastError()
+//	var errorLog retry.Error
+//	if errors.As(retryErr, &errorLog) {
+//		lastErr := errorLog.LastError()
+//	}
 //
 // Note: Using errors.Is or errors.As is preferred as they check ALL wrapped
 // errors, not just the last one.
@@ -392,6 +792,37 @@ func (unrecoverableError) Is(err error) bool {
 	return isUnrecoverable
 }
 
+// ErrUnsatisfactoryResult is recorded for attempts which succeeded with a value rejected by RetryIfResult
+var ErrUnsatisfactoryResult = errors.New("unsatisfactory result")
+
+// ErrMaxElapsedTime is recorded when the retry stops because the next delay would exceed MaxElapsedTime
+var ErrMaxElapsedTime = errors.New("max elapsed time exceeded")
+
+// ErrWouldExceedDeadline is recorded when StopBeforeDeadline stops the retry
+// because the next delay and attempt would not fit before the deadline of the context
+var ErrWouldExceedDeadline = errors.New("next attempt would exceed the context deadline")
+
+// ErrAttemptTimeout is recorded (wrapped around the error returned by the retried function)
+// when a single attempt exceeds the duration set by AttemptTimeout
+var ErrAttemptTimeout = errors.New("attempt timeout")
+
+type attemptTimeoutError struct {
+	error
+}
+
+func (e attemptTimeoutError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrAttemptTimeout, e.error)
+}
+
+func (e attemptTimeoutError) Unwrap() error {
+	return e.error
+}
+
+// Adds support for errors.Is(err, ErrAttemptTimeout)
+func (attemptTimeoutError) Is(err error) bool {
+	return err == ErrAttemptTimeout
+}
+
 func unpackUnrecoverable(err error) error {
 	if unrecoverable, isUnrecoverable := err.(unrecoverableError); isUnrecoverable {
 		return unrecoverable.error
@@ -400,10 +831,111 @@ func unpackUnrecoverable(err error) error {
 	return err
 }
 
-func (r *retrierCore) computeDelay(n uint, err error) time.Duration {
-	delayTime := r.delayType(n, err, r)
-	if r.maxDelay > 0 && delayTime > r.maxDelay {
-		delayTime = r.maxDelay
+// nextDelay computes the delay after failed attempt `n` (started at `start`) and withdraws a retry from the budget.
+// It returns a reason other than StopReasonNone (and no delay) if the retry must not happen.
+func (s *retryState) nextDelay(n uint, start time.Time, err error) (time.Duration, StopReason) {
+	delay := s.computeDelay(n, err)
+	if reason := s.stopReason(n, start, err, delay); reason != StopReasonNone {
+		return 0, reason
+	}
+	if s.budget != nil && !s.budget.Withdraw() {
+		return 0, StopReasonBudgetExhausted
+	}
+	return delay, StopReasonNone
+}
+
+// countStopError counts the failed attempt which returned `err` for StopAfterAttemptsForError
+func (s *retryState) countStopError(err error) {
+	if len(s.stopCounters) == 0 {
+		return
+	}
+	if s.stopCounts == nil {
+		s.stopCounts = &stopCounts{counters: s.stopCounters, counts: make([]uint, len(s.stopCounters))}
+	}
+	s.stopCounts.add(err)
+}
+
+// stopReason checks MaxElapsedTime, StopBeforeDeadline and the StopPolicy after failed attempt
+// number `attempt` (started at `start`) which would be retried after `delay`
+func (s *retryState) stopReason(attempt uint, start time.Time, err error, delay time.Duration) StopReason {
+	if s.maxElapsedTime <= 0 && !s.stopBeforeDeadline && s.stopPolicy == nil {
+		return StopReasonNone
+	}
+
+	now := s.now()
+	elapsed := now.Sub(s.start)
+	if s.maxElapsedTime > 0 && elapsed+delay > s.maxElapsedTime {
+		return StopReasonMaxElapsedTime
+	}
+
+	if s.stopBeforeDeadline {
+		if deadline, ok := s.ctx.Deadline(); ok && time.Until(deadline) < delay+s.estimateAttempt(now.Sub(start)) {
+			return StopReasonWouldExceedDeadline
+		}
+	}
+
+	if s.stopPolicy != nil {
+		state := StopState{Attempt: attempt, Err: err, Elapsed: elapsed, NextDelay: delay, counts: s.stopCounts}
+		if stop, reason := s.stopPolicy.ShouldStop(state); stop {
+			if reason == StopReasonNone {
+				reason = StopReasonPolicy
+			}
+			return reason
+		}
+	}
+
+	return StopReasonNone
+}
+
+// estimateAttempt estimates the duration of the next attempt for StopBeforeDeadline
+// from the duration `last` of the failed one
+func (s *retryState) estimateAttempt(last time.Duration) time.Duration {
+	if s.attemptEstimate == nil {
+		return 0
+	}
+	return s.attemptEstimate(last)
+}
+
+// reasonError returns the sentinel error recorded when the retry stops for `reason`
+func reasonError(reason StopReason) error {
+	switch reason {
+	case StopReasonBudgetExhausted:
+		return ErrBudgetExhausted
+	case StopReasonMaxElapsedTime:
+		return ErrMaxElapsedTime
+	case StopReasonWouldExceedDeadline:
+		return ErrWouldExceedDeadline
+	default:
+		return nil
+	}
+}
+
+// delayState is the DelayContext of a single retry operation.
+// It also provides the previous delay to DelayTypeFuncs asserting for a PreviousDelay method.
+type delayState struct {
+	*retrierCore
+	previousDelay time.Duration
+}
+
+// PreviousDelay returns the delay chosen before the previous attempt, 0 before the first retry
+func (d *delayState) PreviousDelay() time.Duration {
+	return d.previousDelay
+}
+
+// computeDelay returns the delay before the next attempt, clamped to maxDelay.
+// The per-call delay state is allocated lazily, so calls succeeding at the first attempt don't allocate.
+func (s *retryState) computeDelay(n uint, err error) time.Duration {
+	if s.delays == nil {
+		s.delays = &delayState{retrierCore: s.retrierCore}
+	}
+
+	delayTime := s.classifiedDelay
+	if !s.delayClassified {
+		delayTime = s.delayType(n, err, s.delays)
+	}
+	if s.maxDelay > 0 && delayTime > s.maxDelay {
+		delayTime = s.maxDelay
 	}
+	s.delays.previousDelay = delayTime
 	return delayTime
 }
@@ -0,0 +1,96 @@
+package retry
+
+import (
+	"errors"
+	"time"
+)
+
+// retryAfterDelayer is implemented by errors carrying a delay requested by the server,
+// e.g. parsed from the HTTP Retry-After header
+type retryAfterDelayer interface {
+	RetryAfter() time.Duration
+}
+
+// retryAfterTimer is implemented by errors carrying a point in time requested by the server
+type retryAfterTimer interface {
+	RetryAfterTime() time.Time
+}
+
+// RetryAfterDelay is a DelayTypeFunc honoring the delay requested by the failed attempt.
+// It searches the error chain for an error with a `RetryAfter() time.Duration`
+// or `RetryAfterTime() time.Time` method (see WithRetryAfter and WithRetryAfterTime)
+// and returns the requested delay clamped to MaxDelay.
+// If there is no such error or the requested delay is not positive, it delegates to `fallback`.
+//
+//	retry.New(
+//		retry.DelayType(retry.RetryAfterDelay(retry.BackOffDelay)),
+//		retry.MaxDelay(time.Minute),
+//	)
+func RetryAfterDelay(fallback DelayTypeFunc) DelayTypeFunc {
+	return func(n uint, err error, config DelayContext) time.Duration {
+		delay, ok := retryAfter(err)
+		if !ok {
+			return fallback(n, err, config)
+		}
+
+		if maxDelay := config.MaxDelay(); maxDelay > 0 && delay > maxDelay {
+			delay = maxDelay
+		}
+		return delay
+	}
+}
+
+func retryAfter(err error) (time.Duration, bool) {
+	var delayer retryAfterDelayer
+	if errors.As(err, &delayer) {
+		if delay := delayer.RetryAfter(); delay > 0 {
+			return delay, true
+		}
+	}
+
+	var timer retryAfterTimer
+	if errors.As(err, &timer) {
+		if at := timer.RetryAfterTime(); !at.IsZero() {
+			if delay := time.Until(at); delay > 0 {
+				return delay, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+type retryAfterError struct {
+	error
+	delay time.Duration
+	at    time.Time
+}
+
+func (e retryAfterError) Unwrap() error {
+	return e.error
+}
+
+func (e retryAfterError) RetryAfter() time.Duration {
+	if e.at.IsZero() {
+		return e.delay
+	}
+	return time.Until(e.at)
+}
+
+// WithRetryAfter wraps `err` so RetryAfterDelay waits `delay` before the next attempt.
+// The wrapped error keeps the message of `err` and errors.Is/As see through it.
+func WithRetryAfter(err error, delay time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return retryAfterError{error: err, delay: delay}
+}
+
+// WithRetryAfterTime wraps `err` so RetryAfterDelay waits until `at` before the next attempt.
+// The wrapped error keeps the message of `err` and errors.Is/As see through it.
+func WithRetryAfterTime(err error, at time.Time) error {
+	if err == nil {
+		return nil
+	}
+	return retryAfterError{error: err, at: at}
+}
@@ -0,0 +1,94 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRetryAfterError struct {
+	delay time.Duration
+}
+
+func (e testRetryAfterError) Error() string             { return "test" }
+func (e testRetryAfterError) RetryAfter() time.Duration { return e.delay }
+
+type testRetryAfterTimeError struct {
+	at time.Time
+}
+
+func (e testRetryAfterTimeError) Error() string             { return "test" }
+func (e testRetryAfterTimeError) RetryAfterTime() time.Time { return e.at }
+
+func TestRetryAfterDelay(t *testing.T) {
+	config := newRetrieerCore(Delay(time.Second), MaxDelay(time.Minute))
+	delay := RetryAfterDelay(FixedDelay)
+
+	tests := []struct {
+		name     string
+		err      error
+		expected time.Duration
+	}{
+		{"plain error", errors.New("test"), time.Second},
+		{"retry after", testRetryAfterError{5 * time.Second}, 5 * time.Second},
+		{"wrapped retry after", fmt.Errorf("wrap: %w", testRetryAfterError{5 * time.Second}), 5 * time.Second},
+		{"non-positive retry after", testRetryAfterError{-time.Second}, time.Second},
+		{"clamped to max delay", testRetryAfterError{time.Hour}, time.Minute},
+		{"retry after time", testRetryAfterTimeError{time.Now().Add(30 * time.Second)}, 30 * time.Second},
+		{"retry after time in the past", testRetryAfterTimeError{time.Now().Add(-time.Second)}, time.Second},
+		{"with retry after", WithRetryAfter(errors.New("test"), 3*time.Second), 3 * time.Second},
+		{"with retry after time", WithRetryAfterTime(errors.New("test"), time.Now().Add(20*time.Second)), 20 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, delay(1, tt.err, config), float64(100*time.Millisecond))
+		})
+	}
+}
+
+func TestWithRetryAfter(t *testing.T) {
+	testErr := errors.New("test")
+
+	err := WithRetryAfter(testErr, time.Second)
+	assert.Equal(t, "test", err.Error())
+	assert.ErrorIs(t, err, testErr)
+	assert.True(t, IsRecoverable(err))
+	assert.False(t, IsRecoverable(WithRetryAfter(Unrecoverable(testErr), time.Second)))
+
+	assert.NoError(t, WithRetryAfter(nil, time.Second))
+	assert.NoError(t, WithRetryAfterTime(nil, time.Now()))
+}
+
+func TestRetryAfterDelayRetrier(t *testing.T) {
+	var delays []time.Duration
+	attempts := 0
+	err := New(
+		Attempts(3),
+		Delay(time.Millisecond),
+		DelayType(RetryAfterDelay(FixedDelay)),
+		MaxDelay(time.Minute),
+		WithTimer(recordingTimer{&delays}),
+	).Do(func() error {
+		attempts++
+		if attempts == 1 {
+			return WithRetryAfter(errors.New("test"), 2*time.Minute)
+		}
+		return errors.New("test")
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, []time.Duration{time.Minute, time.Millisecond}, delays)
+}
+
+type recordingTimer struct {
+	delays *[]time.Duration
+}
+
+func (t recordingTimer) After(d time.Duration) <-chan time.Time {
+	*t.delays = append(*t.delays, d)
+	return time.After(0)
+}
@@ -0,0 +1,133 @@
+package retry
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExhausted is recorded when a retry is skipped because the RetryBudget has no tokens left
+var ErrBudgetExhausted = errors.New("retry budget exhausted")
+
+// RetryBudget limits the number of retries shared by many retry operations
+// to prevent retry storms when a dependency goes down.
+//
+// Every first attempt that succeeds deposits `ratio` tokens (up to `maxTokens`) and every retry
+// withdraws one token. On top of deposits the budget allows `minPerSecond` retries per second
+// so low traffic callers can still retry.
+// This is similar to retry budgets known from Finagle or retry throttling in gRPC.
+//
+// A RetryBudget is safe for concurrent use:
+//
+//	budget := retry.NewRetryBudget(
+//		retry.BudgetRatio(0.1),
+//		retry.BudgetMinRetriesPerSecond(5),
+//	)
+//
+//	retrier := retry.New(retry.Budget(budget))
+type RetryBudget struct {
+	ratio        float64
+	minPerSecond float64
+	maxTokens    float64
+	now          func() time.Time
+
+	mu       sync.Mutex
+	tokens   float64
+	reserve  float64
+	refilled time.Time
+}
+
+// RetryBudgetOption represents an option for RetryBudget.
+type RetryBudgetOption func(*RetryBudget)
+
+// NewRetryBudget creates a new RetryBudget with the given options.
+func NewRetryBudget(opts ...RetryBudgetOption) *RetryBudget {
+	b := &RetryBudget{
+		ratio:        0.1,
+		minPerSecond: 10,
+		maxTokens:    100,
+		now:          time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	b.reserve = b.minPerSecond
+	b.refilled = b.now()
+
+	return b
+}
+
+// BudgetRatio sets how many retries are earned by every successful first attempt
+// default is 0.1 (one retry per ten successful calls)
+func BudgetRatio(ratio float64) RetryBudgetOption {
+	return func(b *RetryBudget) {
+		b.ratio = ratio
+	}
+}
+
+// BudgetMinRetriesPerSecond sets how many retries per second are allowed regardless of deposits
+// default is 10
+func BudgetMinRetriesPerSecond(minPerSecond uint) RetryBudgetOption {
+	return func(b *RetryBudget) {
+		b.minPerSecond = float64(minPerSecond)
+	}
+}
+
+// BudgetMaxTokens sets the maximum number of retries which can be saved up by deposits
+// default is 100
+func BudgetMaxTokens(maxTokens uint) RetryBudgetOption {
+	return func(b *RetryBudget) {
+		b.maxTokens = float64(maxTokens)
+	}
+}
+
+// BudgetClock provides a way to swap out the source of the current time, useful for testing
+// default is time.Now
+func BudgetClock(now func() time.Time) RetryBudgetOption {
+	if now == nil {
+		return func(*RetryBudget) {}
+	}
+	return func(b *RetryBudget) {
+		b.now = now
+	}
+}
+
+// Deposit records a successful first attempt
+func (b *RetryBudget) Deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens += b.ratio
+	if b.tokens > b.maxTokens {
+		b.tokens = b.maxTokens
+	}
+}
+
+// Withdraw takes one retry from the budget and reports whether the retry is allowed
+func (b *RetryBudget) Withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if elapsed := now.Sub(b.refilled); elapsed > 0 {
+		b.reserve += elapsed.Seconds() * b.minPerSecond
+		if b.reserve > b.minPerSecond {
+			b.reserve = b.minPerSecond
+		}
+		b.refilled = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true
+	}
+
+	if b.reserve >= 1 {
+		b.reserve--
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,96 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryBudget(t *testing.T) {
+	clock := &testClock{now: time.Unix(0, 0)}
+	budget := NewRetryBudget(
+		BudgetRatio(0.5),
+		BudgetMinRetriesPerSecond(2),
+		BudgetMaxTokens(2),
+		BudgetClock(clock.Now),
+	)
+
+	assert.True(t, budget.Withdraw())
+	assert.True(t, budget.Withdraw())
+	assert.False(t, budget.Withdraw(), "reserve is exhausted")
+
+	clock.Advance(500 * time.Millisecond)
+	assert.True(t, budget.Withdraw(), "reserve is refilled over time")
+	assert.False(t, budget.Withdraw())
+
+	for i := 0; i < 10; i++ {
+		budget.Deposit()
+	}
+	assert.True(t, budget.Withdraw())
+	assert.True(t, budget.Withdraw())
+	assert.False(t, budget.Withdraw(), "deposits are capped by max tokens")
+}
+
+func TestBudget(t *testing.T) {
+	clock := &testClock{now: time.Unix(0, 0)}
+	budget := NewRetryBudget(
+		BudgetRatio(1),
+		BudgetMinRetriesPerSecond(2),
+		BudgetClock(clock.Now),
+	)
+	testErr := errors.New("test")
+
+	calls := 0
+	err := New(
+		Attempts(10),
+		Delay(time.Nanosecond),
+		Budget(budget),
+	).Do(
+		func() error {
+			calls++
+			return testErr
+		},
+	)
+	assert.Equal(t, 3, calls, "first attempt and two retries from reserve")
+	assert.ErrorIs(t, err, testErr)
+	assert.ErrorIs(t, err, ErrBudgetExhausted)
+
+	expectedErrorFormat := `All attempts fail:
+#1: test
+#2: test
+#3: test
+#4: retry budget exhausted`
+	assert.Equal(t, expectedErrorFormat, err.Error())
+
+	// successful first attempt earns a retry for the infinite retrier
+	_, err = NewWithData[int](Budget(budget)).Do(
+		func() (int, error) { return 1, nil },
+	)
+	assert.NoError(t, err)
+
+	calls = 0
+	err = New(
+		Attempts(0),
+		Delay(time.Nanosecond),
+		Budget(budget),
+	).Do(
+		func() error {
+			calls++
+			return testErr
+		},
+	)
+	assert.Equal(t, 2, calls)
+	assert.ErrorIs(t, err, testErr)
+	assert.ErrorIs(t, err, ErrBudgetExhausted)
+
+	err = New(
+		Delay(time.Nanosecond),
+		Budget(budget),
+		LastErrorOnly(true),
+	).Do(
+		func() error { return testErr },
+	)
+	assert.Equal(t, Error{testErr, ErrBudgetExhausted}, unwrapStopError(err))
+}
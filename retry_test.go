@@ -34,7 +34,7 @@ func TestDoWithDataAllFailed(t *testing.T) {
 #8: test
 #9: test
 #10: test`
-	assert.Len(t, err, 10)
+	assert.Len(t, unwrapStopError(err), 10)
 	fmt.Println(err.Error())
 	assert.Equal(t, expectedErrorFormat, err.Error(), "retry error format")
 	assert.Equal(t, uint(45), retrySum, "right count of retry")
@@ -88,7 +88,7 @@ func TestRetryIf(t *testing.T) {
 #1: test
 #2: test
 #3: special`
-	assert.Len(t, err, 3)
+	assert.Len(t, unwrapStopError(err), 3)
 	assert.Equal(t, expectedErrorFormat, err.Error(), "retry error format")
 	assert.Equal(t, uint(2), retryCount, "right count of retry")
 }
@@ -114,7 +114,7 @@ func TestRetryIf_ZeroAttempts(t *testing.T) {
 	)
 	assert.Error(t, err)
 
-	assert.Equal(t, "special", err.Error(), "retry error format")
+	assert.Equal(t, "All attempts fail:\n#1: test\n#2: test\n#3: special", err.Error(), "retry error format")
 	assert.Equal(t, retryCount, onRetryCount+1, "right count of retry")
 }
 
@@ -167,7 +167,7 @@ func TestZeroAttemptsWithUnrecoverableError(t *testing.T) {
 		},
 	)
 	assert.Error(t, err)
-	assert.Equal(t, Unrecoverable(assert.AnError), err)
+	assert.Equal(t, Error{assert.AnError}, unwrapStopError(err))
 }
 
 func TestAttemptsForError(t *testing.T) {
@@ -237,7 +237,7 @@ func TestUnrecoverableError(t *testing.T) {
 			return Unrecoverable(testErr)
 		},
 	)
-	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, expectedErr, unwrapStopError(err))
 	assert.Nil(t, errors.Unwrap(err))
 	assert.Equal(t, 1, attempts, "unrecoverable error broke the loop")
 }
@@ -442,7 +442,7 @@ func TestContext(t *testing.T) {
 #1: test
 #2: test
 #3: context canceled`
-		assert.Len(t, err, 3)
+		assert.Len(t, unwrapStopError(err), 3)
 		assert.Equal(t, expectedErrorFormat, err.Error(), "retry error format")
 		assert.Equal(t, 2, retrySum, "called at most once")
 	})
@@ -463,7 +463,7 @@ func TestContext(t *testing.T) {
 		).Do(
 			func() error { return errors.New("test") },
 		)
-		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, context.Canceled, unwrapStopError(err))
 
 		assert.Equal(t, 2, retrySum, "called at most once")
 	})
@@ -487,7 +487,7 @@ func TestContext(t *testing.T) {
 				func() error { return errors.New("test") },
 			)
 
-			assert.Equal(t, context.Canceled, err)
+			assert.ErrorIs(t, err, context.Canceled)
 
 			assert.Equal(t, 2, retrySum, "called at most once")
 		}()
@@ -612,7 +612,7 @@ func TestUnwrap(t *testing.T) {
 	type unwrapper interface {
 		Unwrap() []error
 	}
-	u, ok := err.(unwrapper)
+	u, ok := unwrapStopError(err).(unwrapper)
 	assert.True(t, ok)
 	assert.Equal(t, []error{testError}, u.Unwrap())
 }
@@ -632,7 +632,7 @@ func TestWrappedErrors(t *testing.T) {
 	type wrapper interface {
 		WrappedErrors() []error
 	}
-	w, ok := err.(wrapper)
+	w, ok := unwrapStopError(err).(wrapper)
 	assert.True(t, ok)
 	assert.Equal(t, []error{testError}, w.WrappedErrors())
 }
@@ -776,3 +776,1233 @@ func TestFullJitterBackoffDelay(t *testing.T) {
 		assert.True(t, d <= time.Duration(ceil))
 	}
 }
+
+func TestAttemptTimeout(t *testing.T) {
+	t.Run("timed out attempts are retried", func(t *testing.T) {
+		attempts := 0
+		err := New(
+			Attempts(3),
+			Delay(time.Nanosecond),
+			AttemptTimeout(10*time.Millisecond),
+		).DoCtx(
+			func(ctx context.Context) error {
+				attempts++
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		)
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, ErrAttemptTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 3, attempts)
+
+		expectedErrorFormat := `All attempts fail:
+#1: attempt timeout: context deadline exceeded
+#2: attempt timeout: context deadline exceeded
+#3: attempt timeout: context deadline exceeded`
+		assert.Equal(t, expectedErrorFormat, err.Error(), "retry error format")
+	})
+
+	t.Run("succeeds after timed out attempt", func(t *testing.T) {
+		attempts := 0
+		v, err := NewWithData[int](
+			Delay(time.Nanosecond),
+			AttemptTimeout(10*time.Millisecond),
+		).DoCtx(
+			func(ctx context.Context) (int, error) {
+				attempts++
+				if attempts == 1 {
+					<-ctx.Done()
+					return 0, ctx.Err()
+				}
+				return 42, nil
+			},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, 42, v)
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("other errors are not marked as timeout", func(t *testing.T) {
+		err := New(
+			Attempts(2),
+			Delay(time.Nanosecond),
+			AttemptTimeout(time.Second),
+		).DoCtx(
+			func(ctx context.Context) error {
+				_, hasDeadline := ctx.Deadline()
+				assert.True(t, hasDeadline)
+				return errors.New("test")
+			},
+		)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrAttemptTimeout)
+	})
+
+	t.Run("no timeout by default", func(t *testing.T) {
+		err := New(Attempts(1)).DoCtx(
+			func(ctx context.Context) error {
+				_, hasDeadline := ctx.Deadline()
+				assert.False(t, hasDeadline)
+				return nil
+			},
+		)
+		assert.NoError(t, err)
+	})
+
+	t.Run("infinite attempts", func(t *testing.T) {
+		var retryErrs []error
+		attempts := 0
+		err := New(
+			Attempts(0),
+			Delay(time.Nanosecond),
+			AttemptTimeout(10*time.Millisecond),
+			OnRetry(func(n uint, err error) { retryErrs = append(retryErrs, err) }),
+		).DoCtx(
+			func(ctx context.Context) error {
+				attempts++
+				if attempts < 3 {
+					<-ctx.Done()
+					return ctx.Err()
+				}
+				return nil
+			},
+		)
+		assert.NoError(t, err)
+		assert.Len(t, retryErrs, 2)
+		for _, retryErr := range retryErrs {
+			assert.ErrorIs(t, retryErr, ErrAttemptTimeout)
+		}
+	})
+}
+
+func TestRetryIfResult(t *testing.T) {
+	t.Run("retries unsatisfactory results", func(t *testing.T) {
+		attempts := 0
+		v, err := NewWithData[string](
+			Delay(time.Nanosecond),
+		).RetryIfResult(func(status string, err error) bool {
+			return err != nil || status == "pending"
+		}).Do(
+			func() (string, error) {
+				attempts++
+				if attempts < 3 {
+					return "pending", nil
+				}
+				return "done", nil
+			},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, "done", v)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("returns a copy", func(t *testing.T) {
+		attempts := 0
+		pending := func() (string, error) {
+			attempts++
+			return "pending", nil
+		}
+
+		retrier := NewWithData[string](Attempts(2), Delay(time.Nanosecond))
+		untilDone := retrier.RetryIfResult(func(status string, err error) bool {
+			return err != nil || status == "pending"
+		})
+
+		_, err := retrier.Do(pending)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, attempts, "the retrier itself is not modified")
+
+		_, err = untilDone.With(Attempts(3)).Do(pending)
+		assert.ErrorIs(t, err, ErrUnsatisfactoryResult)
+		assert.Equal(t, 4, attempts, "With keeps the predicate")
+
+		_, err = untilDone.RetryIfResult(nil).Do(pending)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, attempts, "nil removes the predicate")
+	})
+
+	t.Run("returns last unsatisfactory result", func(t *testing.T) {
+		v, err := NewWithData[string](
+			Attempts(3),
+			Delay(time.Nanosecond),
+		).RetryIfResult(func(status string, err error) bool {
+			return err != nil || status == "pending"
+		}).Do(
+			func() (string, error) { return "pending", nil },
+		)
+		assert.ErrorIs(t, err, ErrUnsatisfactoryResult)
+		assert.Len(t, unwrapStopError(err), 3)
+		assert.Equal(t, "pending", v)
+	})
+
+	t.Run("result of failed attempt is not returned", func(t *testing.T) {
+		attempts := 0
+		v, err := NewWithData[string](
+			Attempts(2),
+			Delay(time.Nanosecond),
+		).RetryIfResult(func(status string, err error) bool {
+			return err != nil || status == "pending"
+		}).Do(
+			func() (string, error) {
+				attempts++
+				if attempts == 1 {
+					return "pending", nil
+				}
+				return "partial", errors.New("test")
+			},
+		)
+		assert.Equal(t, "", v)
+		assert.ErrorIs(t, err, ErrUnsatisfactoryResult)
+		assert.EqualError(t, err, "All attempts fail:\n#1: unsatisfactory result\n#2: test")
+	})
+
+	t.Run("errors not accepted by predicate are not retried", func(t *testing.T) {
+		attempts := 0
+		_, err := NewWithData[string](
+			Delay(time.Nanosecond),
+		).RetryIfResult(func(status string, err error) bool {
+			return status == "pending"
+		}).Do(
+			func() (string, error) {
+				attempts++
+				return "", errors.New("test")
+			},
+		)
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("infinite attempts", func(t *testing.T) {
+		attempts := 0
+		v, err := NewWithData[int](
+			Attempts(0),
+			Delay(time.Nanosecond),
+			LastErrorOnly(true),
+		).RetryIfResult(func(v int, err error) bool {
+			return err != nil || v < 5
+		}).Do(
+			func() (int, error) {
+				attempts++
+				return attempts, nil
+			},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, v)
+	})
+
+	t.Run("nil interface values", func(t *testing.T) {
+		attempts := 0
+		_, err := NewWithData[error](
+			Delay(time.Nanosecond),
+		).RetryIfResult(func(v error, err error) bool {
+			return v != nil
+		}).Do(
+			func() (error, error) {
+				attempts++
+				return nil, nil
+			},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+// assertUniform checks that samples are within [lo, hi] and look uniformly distributed
+func assertUniform(t *testing.T, samples []time.Duration, lo, hi time.Duration) {
+	t.Helper()
+
+	var sum float64
+	var buckets [4]int
+	width := float64(hi-lo) / 4
+	for _, d := range samples {
+		if !assert.True(t, d >= lo && d <= hi, "delay %v out of [%v, %v]", d, lo, hi) {
+			return
+		}
+		sum += float64(d)
+		bucket := int(float64(d-lo) / width)
+		if bucket > 3 {
+			bucket = 3
+		}
+		buckets[bucket]++
+	}
+
+	mean := sum / float64(len(samples))
+	assert.InEpsilon(t, float64(lo+hi)/2, mean, 0.02, "mean")
+	for i, count := range buckets {
+		assert.InDelta(t, 0.25, float64(count)/float64(len(samples)), 0.02, "bucket %d", i)
+	}
+}
+
+func TestEqualJitterBackoffDelay(t *testing.T) {
+	const samples = 20000
+	config := New(Delay(100*time.Millisecond), MaxDelay(time.Second))
+
+	tests := []struct {
+		n       uint
+		ceiling time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{100, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.n), func(t *testing.T) {
+			delays := make([]time.Duration, samples)
+			for i := range delays {
+				delays[i] = EqualJitterBackoffDelay(tt.n, nil, config)
+			}
+			assertUniform(t, delays, tt.ceiling/2, tt.ceiling)
+		})
+	}
+
+	// MaxBackOffN limits the ceiling when there is no MaxDelay
+	noMax := New(Delay(time.Second))
+	for i := 0; i < 100; i++ {
+		d := EqualJitterBackoffDelay(200, nil, noMax)
+		assert.True(t, d >= BackOffDelay(200, nil, noMax)/2 && d > 0, "delay %v overflowed", d)
+	}
+
+	assert.Equal(t, time.Duration(0), EqualJitterBackoffDelay(3, nil, New(Delay(0))))
+}
+
+func TestDecorrelatedJitterDelay(t *testing.T) {
+	const samples = 20000
+	base := 100 * time.Millisecond
+
+	tests := []struct {
+		name     string
+		previous time.Duration
+		maxDelay time.Duration
+		lo, hi   time.Duration
+	}{
+		{"first retry", 0, 0, base, 3 * base},
+		{"grows from previous delay", 300 * time.Millisecond, 0, base, 900 * time.Millisecond},
+		{"capped by max delay", 2 * time.Second, time.Second, base, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &delayState{retrierCore: newRetrieerCore(Delay(base), MaxDelay(tt.maxDelay)), previousDelay: tt.previous}
+			delays := make([]time.Duration, samples)
+			for i := range delays {
+				delays[i] = DecorrelatedJitterDelay(1, nil, config)
+			}
+			assertUniform(t, delays, tt.lo, tt.hi)
+		})
+	}
+
+	// MaxBackOffN limits the growth when there is no MaxDelay
+	config := &delayState{retrierCore: newRetrieerCore(Delay(time.Second)), previousDelay: math.MaxInt64}
+	for i := 0; i < 100; i++ {
+		d := DecorrelatedJitterDelay(1, nil, config)
+		assert.True(t, d >= time.Second && d <= time.Second<<config.MaxBackOffN(), "delay %v overflowed", d)
+	}
+
+	// without per-call state it behaves as the first retry
+	assert.True(t, DecorrelatedJitterDelay(1, nil, New(Delay(base))) <= 3*base)
+	assert.Equal(t, time.Second, DecorrelatedJitterDelay(1, nil, New(Delay(2*time.Second), MaxDelay(time.Second))))
+	assert.Equal(t, time.Duration(0), DecorrelatedJitterDelay(1, nil, New(Delay(0))))
+	assert.True(t, DecorrelatedJitterDelay(1, nil, fakeDelayContext{delay: base}) <= 3*base,
+		"DelayContext implementations without PreviousDelay are still supported")
+}
+
+// fakeDelayContext implements only the methods of DelayContext, like configs of users testing their DelayTypeFuncs
+type fakeDelayContext struct {
+	delay time.Duration
+}
+
+func (c fakeDelayContext) Delay() time.Duration     { return c.delay }
+func (c fakeDelayContext) MaxJitter() time.Duration { return 0 }
+func (c fakeDelayContext) MaxBackOffN() uint        { return 62 }
+func (c fakeDelayContext) MaxDelay() time.Duration  { return 0 }
+
+func TestDecorrelatedJitterDelayUsesPreviousDelay(t *testing.T) {
+	var delays []time.Duration
+	err := New(
+		Attempts(20),
+		Delay(time.Millisecond),
+		MaxDelay(time.Hour),
+		DelayType(DecorrelatedJitterDelay),
+		WithTimer(recordingTimer{&delays}),
+	).Do(func() error { return errors.New("test") })
+	assert.Error(t, err)
+
+	assert.Len(t, delays, 19)
+	previous := time.Millisecond
+	for i, d := range delays {
+		assert.True(t, d >= time.Millisecond && d <= 3*previous, "delay %d: %v not within [1ms, %v]", i, d, 3*previous)
+		previous = d
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	config := New(Delay(100 * time.Millisecond))
+	delay := ExponentialBackoff(1.5, 0)
+
+	assert.Equal(t, 100*time.Millisecond, delay(1, nil, config))
+	assert.Equal(t, 150*time.Millisecond, delay(2, nil, config))
+	assert.Equal(t, 225*time.Millisecond, delay(3, nil, config))
+	assert.Equal(t, time.Duration(math.MaxInt64), delay(10000, nil, config), "saturates instead of overflowing")
+	assert.Equal(t, time.Second, delay(10000, nil, New(Delay(100*time.Millisecond), MaxDelay(time.Second))))
+
+	assert.Equal(t, 100*time.Millisecond, ExponentialBackoff(0.5, 0)(3, nil, config), "multiplier < 1 is treated as 1")
+	assert.Equal(t, time.Duration(0), delay(3, nil, New(Delay(0))))
+
+	t.Run("randomization", func(t *testing.T) {
+		delay := ExponentialBackoff(2, 0.5)
+		delays := make([]time.Duration, 20000)
+		for i := range delays {
+			delays[i] = delay(2, nil, config)
+		}
+		assertUniform(t, delays, 100*time.Millisecond, 300*time.Millisecond)
+	})
+
+	t.Run("randomization capped by max delay", func(t *testing.T) {
+		delay := ExponentialBackoff(2, 1)
+		config := New(Delay(100*time.Millisecond), MaxDelay(time.Second))
+		for i := 0; i < 1000; i++ {
+			assert.LessOrEqual(t, delay(100, nil, config), time.Second)
+		}
+		assert.Greater(t, ExponentialBackoff(2, 1)(10000, nil, New(Delay(time.Second))), time.Duration(0), "does not overflow")
+	})
+}
+
+func TestLinearBackoffDelay(t *testing.T) {
+	config := New(Delay(100 * time.Millisecond))
+	delay := LinearBackoffDelay(50 * time.Millisecond)
+
+	assert.Equal(t, 100*time.Millisecond, delay(1, nil, config))
+	assert.Equal(t, 150*time.Millisecond, delay(2, nil, config))
+	assert.Equal(t, 600*time.Millisecond, delay(11, nil, config))
+	assert.Equal(t, time.Duration(math.MaxInt64), LinearBackoffDelay(time.Hour)(math.MaxUint32, nil, New(Delay(math.MaxInt64-1))))
+	assert.Equal(t, time.Duration(math.MaxInt64), LinearBackoffDelay(math.MaxInt64/2)(5, nil, config))
+}
+
+func TestFibonacciBackoffDelay(t *testing.T) {
+	config := New(Delay(100 * time.Millisecond))
+
+	var delays []time.Duration
+	for n := uint(1); n <= 7; n++ {
+		delays = append(delays, FibonacciBackoffDelay(n, nil, config))
+	}
+	assert.Equal(t, []time.Duration{
+		100 * time.Millisecond,
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		300 * time.Millisecond,
+		500 * time.Millisecond,
+		800 * time.Millisecond,
+		1300 * time.Millisecond,
+	}, delays)
+
+	assert.Equal(t, time.Duration(math.MaxInt64), FibonacciBackoffDelay(1000, nil, config))
+	assert.Equal(t, time.Duration(math.MaxInt64), FibonacciBackoffDelay(math.MaxUint32, nil, New(Delay(1))))
+	assert.Equal(t, time.Duration(0), FibonacciBackoffDelay(5, nil, New(Delay(0))))
+}
+
+func TestPolynomialBackoffDelay(t *testing.T) {
+	config := New(Delay(100 * time.Millisecond))
+	delay := PolynomialBackoffDelay(2)
+
+	assert.Equal(t, 100*time.Millisecond, delay(1, nil, config))
+	assert.Equal(t, 400*time.Millisecond, delay(2, nil, config))
+	assert.Equal(t, 900*time.Millisecond, delay(3, nil, config))
+	assert.Equal(t, time.Duration(math.MaxInt64), PolynomialBackoffDelay(10)(math.MaxUint32, nil, config))
+	assert.Equal(t, 100*time.Millisecond, PolynomialBackoffDelay(-1)(7, nil, config), "negative exponent is treated as 0")
+}
+
+func TestGentleBackoffRetrier(t *testing.T) {
+	var delays []time.Duration
+	err := New(
+		Attempts(5),
+		Delay(10*time.Millisecond),
+		MaxDelay(25*time.Millisecond),
+		DelayType(FibonacciBackoffDelay),
+		WithTimer(recordingTimer{&delays}),
+	).Do(func() error { return errors.New("test") })
+
+	assert.Error(t, err)
+	assert.Equal(t, []time.Duration{
+		10 * time.Millisecond,
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		25 * time.Millisecond,
+	}, delays)
+}
+
+func TestMaxElapsedTime(t *testing.T) {
+	testErr := errors.New("test")
+
+	tests := []struct {
+		name        string
+		opts        []Option
+		attemptTime time.Duration
+		attempts    uint
+		expected    error
+	}{
+		{
+			name:     "finite",
+			opts:     []Option{Attempts(10)},
+			attempts: 3,
+			expected: Error{testErr, testErr, testErr, ErrMaxElapsedTime},
+		},
+		{
+			name:     "finite last error only",
+			opts:     []Option{Attempts(10), LastErrorOnly(true)},
+			attempts: 3,
+			expected: Error{testErr, ErrMaxElapsedTime},
+		},
+		{
+			name:     "until succeeded",
+			opts:     []Option{UntilSucceeded()},
+			attempts: 3,
+			expected: Error{testErr, testErr, testErr, ErrMaxElapsedTime},
+		},
+		{
+			name:        "attempt time counts",
+			opts:        []Option{Attempts(10), MaxElapsedTime(100 * time.Millisecond)},
+			attemptTime: 30 * time.Millisecond,
+			attempts:    3,
+			expected:    Error{testErr, testErr, testErr, ErrMaxElapsedTime},
+		},
+		{
+			name:     "attempts exhausted first",
+			opts:     []Option{Attempts(2)},
+			attempts: 2,
+			expected: Error{testErr, testErr},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &testClock{now: time.Unix(0, 0)}
+			var attempts, retries uint
+			opts := append([]Option{
+				Delay(20 * time.Millisecond),
+				DelayType(FixedDelay),
+				MaxElapsedTime(50 * time.Millisecond),
+				WithTimer(clock),
+				OnRetry(func(uint, error) { retries++ }),
+			}, tt.opts...)
+
+			err := New(opts...).Do(func() error {
+				attempts++
+				clock.Advance(tt.attemptTime)
+				return testErr
+			})
+
+			assert.Equal(t, tt.expected, unwrapStopError(err))
+			assert.Equal(t, tt.attempts, attempts)
+			assert.Equal(t, tt.attempts, retries)
+		})
+	}
+}
+
+func TestMaxElapsedTimeObserver(t *testing.T) {
+	clock := &testClock{now: time.Unix(0, 0)}
+	observer := &recordingObserver{}
+
+	err := New(
+		Attempts(10),
+		Delay(time.Second),
+		DelayType(FixedDelay),
+		MaxElapsedTime(1500*time.Millisecond),
+		WithTimer(clock),
+		WithObserver(observer),
+	).Do(func() error { return errors.New("test") })
+
+	assert.ErrorIs(t, err, ErrMaxElapsedTime)
+	assert.Equal(t, StopReasonMaxElapsedTime, observer.reason)
+	assert.Equal(t, "max_elapsed_time", StopReasonMaxElapsedTime.String())
+}
+
+// unwrapStopError returns the error wrapped by the StopError returned by a retrier
+func unwrapStopError(err error) error {
+	var stopErr *StopError
+	if errors.As(err, &stopErr) {
+		return stopErr.Err()
+	}
+	return err
+}
+
+func TestErrorStopReason(t *testing.T) {
+	testErr := errors.New("test")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cancelled, cancelCancelled := context.WithCancel(context.Background())
+	cancelCancelled()
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		err      error
+		reason   StopReason
+		attempts uint
+	}{
+		{"attempts exhausted", []Option{Attempts(3)}, testErr, StopReasonAttemptsExhausted, 3},
+		{"attempts for error", []Option{Attempts(5), AttemptsForError(2, testErr)}, testErr, StopReasonAttemptsForErrorExhausted, 2},
+		{"unrecoverable", []Option{Attempts(5)}, Unrecoverable(testErr), StopReasonUnrecoverable, 1},
+		{"retry if", []Option{Attempts(5), RetryIf(func(error) bool { return false })}, testErr, StopReasonRetryIf, 1},
+		{"context", []Option{Attempts(5), Delay(time.Hour), Context(ctx)}, testErr, StopReasonContext, 1},
+		{"context done before the first attempt", []Option{Attempts(5), Context(cancelled)}, testErr, StopReasonContext, 0},
+		{"max elapsed time", []Option{Attempts(5), MaxElapsedTime(time.Nanosecond)}, testErr, StopReasonMaxElapsedTime, 1},
+		{"budget", []Option{Attempts(5), Budget(NewRetryBudget(BudgetMinRetriesPerSecond(0)))}, testErr, StopReasonBudgetExhausted, 1},
+		{"last error only", []Option{Attempts(3), LastErrorOnly(true)}, testErr, StopReasonAttemptsExhausted, 3},
+		{"last error only unrecoverable", []Option{Attempts(5), LastErrorOnly(true)}, Unrecoverable(testErr), StopReasonUnrecoverable, 1},
+		{"until succeeded with last error only", []Option{Attempts(0), LastErrorOnly(true), RetryIf(func(error) bool { return false })}, testErr, StopReasonRetryIf, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{Delay(time.Nanosecond), DelayType(FixedDelay)}, tt.opts...)
+			err := New(opts...).Do(func() error {
+				if tt.reason == StopReasonContext {
+					cancel()
+				}
+				return tt.err
+			})
+
+			assert.Equal(t, tt.reason, ReasonOf(err))
+			assert.Equal(t, tt.reason, ReasonOf(fmt.Errorf("wrapped: %w", err)))
+
+			var stopErr *StopError
+			if assert.ErrorAs(t, err, &stopErr) {
+				assert.Equal(t, tt.reason, stopErr.StopReason())
+				assert.Equal(t, tt.attempts, stopErr.AttemptCount())
+				assert.Equal(t, err.Error(), stopErr.Err().Error())
+			}
+		})
+	}
+}
+
+func TestStopError(t *testing.T) {
+	clock := &testClock{now: time.Unix(0, 0)}
+	testErr := errors.New("test")
+
+	err := New(
+		Attempts(3),
+		Delay(time.Second),
+		DelayType(FixedDelay),
+		WithTimer(clock),
+	).Do(func() error {
+		clock.Advance(100 * time.Millisecond)
+		return testErr
+	})
+
+	var stopErr *StopError
+	assert.ErrorAs(t, err, &stopErr)
+	assert.Equal(t, StopReasonAttemptsExhausted, stopErr.StopReason())
+	assert.Equal(t, uint(3), stopErr.AttemptCount())
+	assert.Equal(t, 2300*time.Millisecond, stopErr.Elapsed())
+	assert.Equal(t, Error{testErr, testErr, testErr}, stopErr.Err())
+	assert.Equal(t, Error{testErr, testErr, testErr}.Error(), err.Error())
+	assert.Equal(t, []error{stopErr.Err()}, stopErr.WrappedErrors())
+
+	var retryErr Error
+	assert.ErrorAs(t, err, &retryErr, "the Error is wrapped")
+	assert.Equal(t, testErr, retryErr.LastError())
+	retryErr = append(retryErr, errors.New("appended"))
+	assert.Equal(t, uint(3), stopErr.AttemptCount(), "appending to the Error doesn't affect the StopError")
+
+	assert.Equal(t, StopReasonNone, ReasonOf(Error{testErr}))
+	assert.Equal(t, StopReasonNone, ReasonOf(testErr))
+	assert.Equal(t, StopReasonNone, ReasonOf(nil))
+}
+
+func TestErrorAttempts(t *testing.T) {
+	clock := &testClock{now: time.Unix(0, 0)}
+	testErr := errors.New("test")
+	var attempts int
+
+	err := New(
+		Attempts(5),
+		Delay(time.Second),
+		DelayType(BackOffDelay),
+		WithTimer(clock),
+	).Do(func() error {
+		attempts++
+		clock.Advance(time.Duration(attempts) * 10 * time.Millisecond)
+		if attempts == 3 {
+			return Unrecoverable(testErr)
+		}
+		return testErr
+	})
+
+	var stopErr *StopError
+	assert.ErrorAs(t, err, &stopErr)
+	assert.Equal(t, []AttemptRecord{
+		{Attempt: 1, Start: time.Unix(0, 0), Duration: 10 * time.Millisecond, Delay: time.Second, Err: testErr},
+		{Attempt: 2, Start: time.Unix(1, int64(10*time.Millisecond)), Duration: 20 * time.Millisecond, Delay: 2 * time.Second, Err: testErr},
+		{Attempt: 3, Start: time.Unix(3, int64(30*time.Millisecond)), Duration: 30 * time.Millisecond, Err: testErr},
+	}, stopErr.Attempts())
+	assert.Equal(t, Error{testErr, testErr, testErr}, stopErr.Err())
+
+	records := stopErr.Attempts()
+	records[0].Attempt = 100
+	assert.Equal(t, uint(1), stopErr.Attempts()[0].Attempt, "records are copied")
+}
+
+func TestErrorAttemptsBoundedByDefault(t *testing.T) {
+	err := New(Attempts(150), Delay(0), DelayType(FixedDelay)).Do(func() error {
+		return errors.New("test")
+	})
+
+	var stopErr *StopError
+	assert.ErrorAs(t, err, &stopErr)
+	assert.Len(t, stopErr.Err(), 150, "the error log is not limited")
+	records := stopErr.Attempts()
+	assert.Len(t, records, 100)
+	assert.Equal(t, uint(50), records[49].Attempt)
+	assert.Equal(t, uint(101), records[50].Attempt)
+	assert.Equal(t, uint(150), records[99].Attempt)
+}
+
+type testHistoryError struct {
+	attempt int
+}
+
+func (e *testHistoryError) Error() string {
+	return fmt.Sprintf("attempt %d", e.attempt)
+}
+
+func TestErrorHistoryLimit(t *testing.T) {
+	errQuota := errors.New("quota")
+	var attempts int
+
+	err := New(
+		Attempts(10),
+		Delay(0),
+		DelayType(FixedDelay),
+		ErrorHistoryLimit(4),
+	).Do(func() error {
+		attempts++
+		if attempts == 3 {
+			return fmt.Errorf("wrapped: %w", errQuota)
+		}
+		return &testHistoryError{attempts}
+	})
+
+	var stopErr *StopError
+	assert.ErrorAs(t, err, &stopErr)
+	assert.Len(t, stopErr.Err(), 4)
+	assert.Equal(t, "All attempts fail:\n"+
+		"#1: attempt 1\n"+
+		"#2: attempt 2\n"+
+		"... 6 more ...\n"+
+		"#9: attempt 9\n"+
+		"#10: attempt 10", err.Error())
+
+	assert.ErrorIs(t, err, errQuota, "elided errors are still matched")
+	var historyErr *testHistoryError
+	assert.ErrorAs(t, err, &historyErr)
+
+	assert.Equal(t, uint(10), stopErr.AttemptCount())
+	var recorded []uint
+	for _, record := range stopErr.Attempts() {
+		recorded = append(recorded, record.Attempt)
+	}
+	assert.Equal(t, []uint{1, 2, 9, 10}, recorded)
+}
+
+func TestErrorHistoryLimitUntilSucceeded(t *testing.T) {
+	err := New(
+		UntilSucceeded(),
+		Stop(StopAfterAttempts(250)),
+		Delay(0),
+		DelayType(FixedDelay),
+	).Do(func() error { return errors.New("test") })
+
+	var stopErr *StopError
+	assert.ErrorAs(t, err, &stopErr)
+	assert.Equal(t, uint(250), stopErr.AttemptCount())
+	assert.Len(t, stopErr.Err(), 100, "the error log is bounded by default")
+	assert.Len(t, stopErr.Attempts(), 100)
+	assert.Contains(t, err.Error(), "... 150 more ...")
+
+	err = New(
+		UntilSucceeded(),
+		Stop(StopAfterAttempts(250)),
+		Delay(0),
+		DelayType(FixedDelay),
+		ErrorHistoryLimit(1000),
+	).Do(func() error { return errors.New("test") })
+
+	assert.ErrorAs(t, err, &stopErr)
+	assert.Len(t, stopErr.Err(), 250)
+}
+
+func TestErrorHistoryLimitDeduplicatesElided(t *testing.T) {
+	var attempts int
+	retrier := New(Attempts(1000), Delay(0), DelayType(FixedDelay), ErrorHistoryLimit(2))
+
+	err := retrier.Do(func() error {
+		attempts++
+		return &testHistoryError{attempts % 3}
+	})
+
+	var stopErr *StopError
+	assert.ErrorAs(t, err, &stopErr)
+	assert.Len(t, stopErr.Err(), 2)
+	assert.Contains(t, err.Error(), "... 998 more ...")
+	assert.Len(t, stopErr.Unwrap(), 3, "up to 2 distinct sentinels are tracked")
+}
+
+func TestErrorHistoryLimitTracksElidedChains(t *testing.T) {
+	errQuota := errors.New("quota")
+	errOther := errors.New("other")
+	var attempts int
+
+	err := New(Attempts(1000), Delay(0), DelayType(FixedDelay), ErrorHistoryLimit(2)).Do(func() error {
+		attempts++
+		switch attempts {
+		case 500:
+			return fmt.Errorf("attempt %d: %w", attempts, errOther)
+		case 600:
+			return &os.PathError{Op: "open", Path: "config", Err: os.ErrNotExist}
+		default:
+			return fmt.Errorf("attempt %d: %w", attempts, errQuota)
+		}
+	})
+
+	assert.ErrorIs(t, err, errQuota)
+	assert.ErrorIs(t, err, errOther, "sentinels are tracked whatever the messages of the errors wrapping them")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	var pathErr *os.PathError
+	assert.ErrorAs(t, err, &pathErr)
+
+	var stopErr *StopError
+	assert.ErrorAs(t, err, &stopErr)
+	assert.Len(t, stopErr.Unwrap(), 4, "one elided error is kept per new error type or sentinel")
+}
+
+func TestErrorHistoryLimitLastErrorOnly(t *testing.T) {
+	testErr := errors.New("test")
+	err := New(
+		Attempts(5),
+		Delay(0),
+		DelayType(FixedDelay),
+		ErrorHistoryLimit(2),
+		LastErrorOnly(true),
+		Budget(NewRetryBudget(BudgetMinRetriesPerSecond(0))),
+	).Do(func() error { return testErr })
+
+	assert.Equal(t, Error{testErr, ErrBudgetExhausted}, unwrapStopError(err))
+	assert.Equal(t, "All attempts fail:\n#1: test\n#2: retry budget exhausted", err.Error())
+}
+
+func TestStopBeforeDeadline(t *testing.T) {
+	testErr := errors.New("test")
+
+	t.Run("delay exceeds deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		var attempts int
+		start := time.Now()
+		err := New(
+			Attempts(5),
+			Delay(time.Hour),
+			DelayType(FixedDelay),
+			Context(ctx),
+			StopBeforeDeadline(true),
+		).Do(func() error {
+			attempts++
+			return testErr
+		})
+
+		assert.Less(t, time.Since(start), time.Second, "does not wait for the deadline")
+		assert.Equal(t, 1, attempts)
+		assert.Equal(t, Error{testErr, ErrWouldExceedDeadline}, unwrapStopError(err))
+		assert.Equal(t, StopReasonWouldExceedDeadline, ReasonOf(err))
+	})
+
+	t.Run("until succeeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		err := New(
+			UntilSucceeded(),
+			Delay(time.Hour),
+			DelayType(FixedDelay),
+			Context(ctx),
+			StopBeforeDeadline(true),
+		).Do(func() error { return testErr })
+
+		assert.Equal(t, Error{testErr, ErrWouldExceedDeadline}, unwrapStopError(err))
+	})
+
+	t.Run("deadline on the wall clock", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		// the clock of the Timer is far from the wall clock and its After fires immediately
+		clock := &testClock{now: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)}
+		var attempts int
+		err := New(
+			Attempts(5),
+			Delay(time.Hour),
+			DelayType(FixedDelay),
+			WithTimer(clock),
+			Context(ctx),
+			StopBeforeDeadline(true),
+		).Do(func() error {
+			attempts++
+			return testErr
+		})
+
+		assert.Equal(t, 1, attempts)
+		assert.Equal(t, StopReasonWouldExceedDeadline, ReasonOf(err))
+	})
+
+	estimates := []struct {
+		name     string
+		opts     []Option
+		attempts int
+		reason   StopReason
+	}{
+		// the first attempt takes 30s of the Timer clock, the second one 60s
+		{"attempt duration counts", nil, 2, StopReasonWouldExceedDeadline},
+		{"fixed estimate", []Option{AttemptEstimate(func(time.Duration) time.Duration { return 90 * time.Second })}, 1, StopReasonWouldExceedDeadline},
+		{"no estimate", []Option{AttemptEstimate(nil)}, 5, StopReasonAttemptsExhausted},
+	}
+	for _, tt := range estimates {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			clock := &testClock{now: time.Unix(0, 0)}
+			var attempts int
+			opts := append([]Option{
+				Attempts(5),
+				Delay(time.Second),
+				DelayType(FixedDelay),
+				WithTimer(clock),
+				Context(ctx),
+				StopBeforeDeadline(true),
+			}, tt.opts...)
+			err := New(opts...).Do(func() error {
+				attempts++
+				clock.Advance(time.Duration(attempts) * 30 * time.Second)
+				return testErr
+			})
+
+			assert.Equal(t, tt.attempts, attempts)
+			assert.Equal(t, tt.reason, ReasonOf(err))
+		})
+	}
+
+	t.Run("without deadline", func(t *testing.T) {
+		var attempts int
+		err := New(
+			Attempts(3),
+			Delay(time.Millisecond),
+			DelayType(FixedDelay),
+			StopBeforeDeadline(true),
+		).Do(func() error {
+			attempts++
+			return testErr
+		})
+
+		assert.Equal(t, 3, attempts)
+		assert.Equal(t, Error{testErr, testErr, testErr}, unwrapStopError(err))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := New(
+			Attempts(5),
+			Delay(time.Hour),
+			DelayType(FixedDelay),
+			Context(ctx),
+		).Do(func() error { return testErr })
+
+		assert.Equal(t, Error{testErr, context.DeadlineExceeded}, unwrapStopError(err))
+	})
+}
+
+func TestRetrierWith(t *testing.T) {
+	errQuota := errors.New("quota")
+	var baseRetries, callRetries int
+	base := New(
+		Attempts(5),
+		Delay(time.Nanosecond),
+		DelayType(FixedDelay),
+		AttemptsForError(3, errQuota),
+		OnRetry(func(uint, error) { baseRetries++ }),
+	)
+
+	call := base.With(
+		Attempts(2),
+		AttemptsForError(1, errQuota),
+		OnRetry(func(uint, error) { callRetries++ }),
+	)
+
+	err := call.Do(func() error { return errors.New("test") })
+	assert.Len(t, unwrapStopError(err), 2)
+	assert.Equal(t, 0, baseRetries)
+	assert.Equal(t, 2, callRetries, "OnRetry is replaced, not added")
+
+	err = call.Do(func() error { return errQuota })
+	assert.Len(t, unwrapStopError(err), 1)
+
+	baseRetries, callRetries = 0, 0
+	err = base.Do(func() error { return errors.New("test") })
+	assert.Len(t, unwrapStopError(err), 5, "base retrier is not modified")
+	assert.Equal(t, 5, baseRetries)
+	assert.Equal(t, 0, callRetries)
+	assert.Equal(t, uint(3), base.attemptsForError[errQuota])
+
+	err = base.Do(func() error { return errQuota })
+	assert.Len(t, unwrapStopError(err), 3)
+}
+
+func TestRetrierWithObservers(t *testing.T) {
+	baseObserver := &recordingObserver{}
+	base := New(Attempts(1), WithObserver(baseObserver), WithObserver(NopObserver{}))
+
+	callObserver := &recordingObserver{}
+	first := base.With(WithObserver(callObserver))
+	second := base.With(WithObserver(NopObserver{}))
+
+	_ = first.Do(func() error { return nil })
+	_ = second.Do(func() error { return nil })
+
+	assert.Len(t, base.observers, 2)
+	assert.Len(t, first.observers, 3)
+	assert.Equal(t, callObserver, first.observers[2], "observers of copies don't overwrite each other")
+	assert.Len(t, baseObserver.events, 6)
+	assert.Len(t, callObserver.events, 3)
+}
+
+func TestRetrierWithDelay(t *testing.T) {
+	var delays []time.Duration
+	base := New(Attempts(3), Delay(time.Second), DelayType(BackOffDelay), WithTimer(recordingTimer{&delays}))
+
+	err := base.With(Delay(time.Millisecond)).Do(func() error { return errors.New("test") })
+	assert.Error(t, err)
+	assert.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond}, delays)
+	assert.Equal(t, time.Second, base.Delay())
+}
+
+func TestDoWithContext(t *testing.T) {
+	retrier := New(Attempts(3), Delay(time.Hour), DelayType(FixedDelay))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var attempts int
+	err := retrier.DoWithContext(ctx, func() error {
+		attempts++
+		cancel()
+		return errors.New("test")
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, attempts)
+
+	v, err := NewWithData[int](Attempts(3)).DoWithContext(context.Background(), func() (int, error) {
+		return 42, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 42, v)
+
+	v, err = NewWithData[int](Attempts(3)).With(Attempts(1)).DoWithContext(ctx, func() (int, error) {
+		return 42, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled, "cancelled context is checked before the first attempt")
+	assert.Equal(t, 0, v)
+}
+
+func TestDoContext(t *testing.T) {
+	type key struct{}
+	retrier := New(Attempts(3), Delay(time.Hour), DelayType(FixedDelay))
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "request"))
+	var attempts int
+	err := retrier.DoContext(ctx, func(ctx context.Context) error {
+		attempts++
+		assert.Equal(t, "request", ctx.Value(key{}), "the function receives the request context")
+		cancel()
+		return errors.New("test")
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, attempts)
+
+	attempts = 0
+	err = New(UntilSucceeded(), Delay(time.Hour)).DoContext(ctx, func(ctx context.Context) error {
+		attempts++
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, attempts)
+
+	v, err := NewWithData[string](
+		Attempts(3),
+		Delay(time.Nanosecond),
+		AttemptTimeout(time.Hour),
+	).DoContext(context.WithValue(context.Background(), key{}, "request"), func(ctx context.Context) (string, error) {
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline, "attempt context is derived from the request context")
+		return ctx.Value(key{}).(string), nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "request", v)
+}
+
+func TestDoContextUntilSucceeded(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var attempts int
+	err := New(UntilSucceeded(), Delay(time.Hour), DelayType(FixedDelay)).DoContext(ctx, func(ctx context.Context) error {
+		attempts++
+		cancel()
+		return errors.New("test")
+	})
+	assert.ErrorIs(t, err, context.Canceled, "the request context cancels the infinite loop too")
+	assert.Equal(t, 1, attempts)
+}
+
+func TestLoopConformance(t *testing.T) {
+	testErr := errors.New("test")
+	specialErr := errors.New("special")
+
+	scenarios := []struct {
+		name     string
+		opts     []Option
+		fn       func(attempt uint, cancel context.CancelFunc) error
+		attempts uint
+		expected error
+		// reason is the reason given up with, StopReasonNone on success
+		reason StopReason
+	}{
+		{
+			name:     "attempts exhausted",
+			fn:       func(uint, context.CancelFunc) error { return testErr },
+			attempts: 3,
+			expected: Error{testErr, testErr, testErr},
+			reason:   StopReasonAttemptsExhausted,
+		},
+		{
+			name: "success",
+			fn: func(attempt uint, _ context.CancelFunc) error {
+				if attempt < 2 {
+					return testErr
+				}
+				return nil
+			},
+			attempts: 2,
+		},
+		{
+			name:     "attempts for error",
+			opts:     []Option{AttemptsForError(2, specialErr)},
+			fn:       func(uint, context.CancelFunc) error { return specialErr },
+			attempts: 2,
+			expected: Error{specialErr, specialErr},
+			reason:   StopReasonAttemptsForErrorExhausted,
+		},
+		{
+			name: "retry if",
+			opts: []Option{RetryIf(func(err error) bool { return err != specialErr })},
+			fn: func(attempt uint, _ context.CancelFunc) error {
+				if attempt < 2 {
+					return testErr
+				}
+				return specialErr
+			},
+			attempts: 2,
+			expected: Error{testErr, specialErr},
+			reason:   StopReasonRetryIf,
+		},
+		{
+			name: "unrecoverable",
+			fn: func(attempt uint, _ context.CancelFunc) error {
+				if attempt < 2 {
+					return testErr
+				}
+				return Unrecoverable(specialErr)
+			},
+			attempts: 2,
+			expected: Error{testErr, specialErr},
+			reason:   StopReasonUnrecoverable,
+		},
+		{
+			name: "context",
+			fn: func(_ uint, cancel context.CancelFunc) error {
+				cancel()
+				return testErr
+			},
+			attempts: 1,
+			expected: Error{testErr, context.Canceled},
+			reason:   StopReasonContext,
+		},
+	}
+
+	loops := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "finite", opts: []Option{Attempts(3)}},
+		{name: "until succeeded", opts: []Option{UntilSucceeded(), Stop(StopAfterAttempts(3))}},
+	}
+
+	type outcome struct {
+		err      error
+		reason   StopReason
+		attempts uint
+		events   []string
+	}
+
+	for _, scenario := range scenarios {
+		for _, lastErrorOnly := range []bool{false, true} {
+			for _, wrapContextError := range []bool{false, true} {
+				for _, historyLimit := range []uint{0, 2} {
+					name := fmt.Sprintf("%s/last_error_only=%t/wrap_context_error=%t/history_limit=%d",
+						scenario.name, lastErrorOnly, wrapContextError, historyLimit)
+					t.Run(name, func(t *testing.T) {
+						outcomes := make([]outcome, len(loops))
+						for i, loop := range loops {
+							ctx, cancel := context.WithCancel(context.Background())
+							defer cancel()
+
+							var o outcome
+							observer := &recordingObserver{}
+							opts := append([]Option{
+								Context(ctx),
+								Delay(time.Hour),
+								DelayType(FixedDelay),
+								LastErrorOnly(lastErrorOnly),
+								WrapContextErrorWithLastError(wrapContextError),
+								ErrorHistoryLimit(historyLimit),
+								WithObserver(observer),
+							}, loop.opts...)
+							opts = append(opts, scenario.opts...)
+							if scenario.reason != StopReasonContext {
+								opts = append(opts, WithTimer(&testClock{now: time.Unix(0, 0)}))
+							}
+
+							o.err = unwrapStopError(New(opts...).Do(func() error {
+								o.attempts++
+								return scenario.fn(o.attempts, cancel)
+							}))
+							o.reason = observer.reason
+							o.events = observer.events
+							outcomes[i] = o
+						}
+
+						assert.Equal(t, scenario.reason, outcomes[0].reason)
+						assert.Equal(t, scenario.attempts, outcomes[0].attempts)
+						if !lastErrorOnly && historyLimit == 0 {
+							assert.Equal(t, scenario.expected, outcomes[0].err)
+						}
+						if scenario.expected != nil {
+							assert.ErrorIs(t, outcomes[0].err, scenario.expected.(Error).LastError())
+						}
+						assert.Equal(t, outcomes[0], outcomes[1], "finite and infinite loops behave the same")
+					})
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,245 @@
+/*
+Package retryhttp provides an http.RoundTripper retrying requests using retry.Retrier.
+
+	client := &http.Client{
+		Transport: retryhttp.NewTransport(http.DefaultTransport, retry.New(
+			retry.Attempts(5),
+			retry.DelayType(retry.RetryAfterDelay(retry.BackOffDelay)),
+		)),
+	}
+
+By default only idempotent requests (see IsIdempotent) are retried, on transport errors
+and on 408, 429, 500, 502, 503 and 504 responses. Requests with a body are retried only
+when the body can be rewound by http.Request.GetBody (which http.NewRequest sets for the common body types).
+
+Retry-After headers of retryable responses are honored when the retrier uses retry.RetryAfterDelay.
+
+When all attempts fail on a retryable status, the last response is returned to the caller as is.
+The context of the request is used for the retries, so cancelling it also stops waiting between attempts.
+*/
+package retryhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/avast/retry-go/v5"
+)
+
+// maxDrainBytes limits how much of a discarded response body is read,
+// so the connection can be reused without reading huge bodies.
+const maxDrainBytes = 4 << 10
+
+// ShouldRetryFunc decides if the response or transport error of an attempt is retryable
+type ShouldRetryFunc func(resp *http.Response, err error) bool
+
+// Transport is an http.RoundTripper retrying requests of the underlying transport
+type Transport struct {
+	base        http.RoundTripper
+	retrier     *retry.Retrier
+	shouldRetry ShouldRetryFunc
+	isRetryable func(*http.Request) bool
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// Option represents an option for Transport.
+type Option func(*Transport)
+
+// NewTransport creates a new Transport retrying requests of `base` with `retrier`.
+// nil `base` means http.DefaultTransport,
+// nil `retrier` means retry.New with retry.RetryAfterDelay(retry.BackOffDelay)
+func NewTransport(base http.RoundTripper, retrier *retry.Retrier, opts ...Option) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if retrier == nil {
+		retrier = retry.New(retry.DelayType(retry.RetryAfterDelay(retry.BackOffDelay)))
+	}
+
+	t := &Transport{
+		base:        base,
+		retrier:     retrier,
+		shouldRetry: DefaultShouldRetry,
+		isRetryable: IsIdempotent,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// ShouldRetry sets the classification of responses and transport errors
+// default is DefaultShouldRetry
+func ShouldRetry(shouldRetry ShouldRetryFunc) Option {
+	return func(t *Transport) {
+		if shouldRetry != nil {
+			t.shouldRetry = shouldRetry
+		}
+	}
+}
+
+// RetryRequestIf sets which requests may be retried at all,
+// e.g. to retry POST requests known to be safe
+// default is IsIdempotent
+func RetryRequestIf(isRetryable func(*http.Request) bool) Option {
+	return func(t *Transport) {
+		if isRetryable != nil {
+			t.isRetryable = isRetryable
+		}
+	}
+}
+
+// DefaultShouldRetry retries on transport errors (except the cancellation of the request)
+// and on 408, 429, 500, 502, 503 and 504 responses
+func DefaultShouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// IsIdempotent reports whether the request is idempotent:
+// GET, HEAD, OPTIONS, TRACE, PUT and DELETE requests,
+// or any request with an Idempotency-Key or X-Idempotency-Key header (same as net/http)
+func IsIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	_, hasKey := req.Header["Idempotency-Key"]
+	_, hasXKey := req.Header["X-Idempotency-Key"]
+	return hasKey || hasXKey
+}
+
+// StatusError is the error of an attempt which ended with a retryable status code
+type StatusError struct {
+	StatusCode int
+	Status     string
+
+	retryAfter time.Duration
+}
+
+// Error implements error
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("retryable HTTP status: %s", e.Status)
+}
+
+// RetryAfter returns the delay requested by the Retry-After header of the response,
+// 0 if the header is missing or invalid. It is honored by retry.RetryAfterDelay.
+func (e *StatusError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	if !rewindable || !t.isRetryable(req) {
+		return t.base.RoundTrip(req)
+	}
+
+	var (
+		resp     *http.Response
+		attempts int
+	)
+
+	// the attempt context is not used for the request, it is cancelled when the attempt returns,
+	// before the caller reads the response body
+	err := t.retrier.DoWithContext(req.Context(), func() error {
+		if resp != nil {
+			drain(resp)
+			resp = nil
+		}
+
+		attemptReq := req
+		if attempts > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return retry.Unrecoverable(err)
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+		attempts++
+
+		r, err := t.base.RoundTrip(attemptReq)
+		if !t.shouldRetry(r, err) {
+			if err != nil {
+				return retry.Unrecoverable(err)
+			}
+			resp = r
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resp = r
+		return &StatusError{
+			StatusCode: r.StatusCode,
+			Status:     r.Status,
+			retryAfter: parseRetryAfter(r.Header.Get("Retry-After"), time.Now()),
+		}
+	})
+
+	if resp != nil && (err == nil || req.Context().Err() == nil) {
+		// success, or the retryable response of the last attempt
+		return resp, nil
+	}
+	if resp != nil {
+		drain(resp)
+	}
+
+	return nil, err
+}
+
+// drain reads a bit of the discarded response body and closes it, so the connection can be reused
+func drain(resp *http.Response) {
+	_, _ = io.CopyN(io.Discard, resp.Body, maxDrainBytes)
+	_ = resp.Body.Close()
+}
+
+// parseRetryAfter parses the Retry-After header, either delay-seconds or an HTTP-date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		if seconds > int64(math.MaxInt64/time.Second) {
+			return math.MaxInt64
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
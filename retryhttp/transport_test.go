@@ -0,0 +1,282 @@
+package retryhttp_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/avast/retry-go/v5"
+	"github.com/avast/retry-go/v5/retryhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func newClient(retrier *retry.Retrier, opts ...retryhttp.Option) *http.Client {
+	return &http.Client{Transport: retryhttp.NewTransport(nil, retrier, opts...)}
+}
+
+func TestTransportRetriesStatus(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.WriteString(w, "hello")
+	}))
+	defer ts.Close()
+
+	client := newClient(retry.New(retry.Attempts(3), retry.Delay(time.Millisecond)))
+
+	resp, err := client.Get(ts.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(body))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestTransportReturnsLastResponse(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = io.WriteString(w, "bad gateway")
+	}))
+	defer ts.Close()
+
+	client := newClient(retry.New(retry.Attempts(2), retry.Delay(time.Millisecond)))
+
+	resp, err := client.Get(ts.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "bad gateway", string(body), "last response body is not drained")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestTransportDoesNotRetry(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		header http.Header
+		status int
+		calls  int32
+	}{
+		{"non-retryable status", http.MethodGet, nil, http.StatusNotFound, 1},
+		{"non-idempotent method", http.MethodPost, nil, http.StatusServiceUnavailable, 1},
+		{"idempotency key", http.MethodPost, http.Header{"Idempotency-Key": {"1"}}, http.StatusServiceUnavailable, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(tt.status)
+			}))
+			defer ts.Close()
+
+			client := newClient(retry.New(retry.Attempts(3), retry.Delay(time.Millisecond)))
+
+			req, err := http.NewRequest(tt.method, ts.URL, strings.NewReader("body"))
+			assert.NoError(t, err)
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+
+			resp, err := client.Do(req)
+			assert.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.calls, atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func TestTransportRewindsBody(t *testing.T) {
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) < 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	client := newClient(retry.New(retry.Attempts(2), retry.Delay(time.Millisecond)))
+
+	req, err := http.NewRequest(http.MethodPut, ts.URL, strings.NewReader("payload"))
+	assert.NoError(t, err)
+
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"payload", "payload"}, bodies)
+}
+
+func TestTransportUnrewindableBody(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	client := newClient(retry.New(retry.Attempts(3), retry.Delay(time.Millisecond)))
+
+	req, err := http.NewRequest(http.MethodPut, ts.URL, io.NopCloser(strings.NewReader("payload")))
+	assert.NoError(t, err)
+	assert.Nil(t, req.GetBody)
+
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestTransportRetryAfter(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer ts.Close()
+
+	var delays []time.Duration
+	client := newClient(retry.New(
+		retry.Attempts(2),
+		retry.Delay(time.Millisecond),
+		retry.DelayType(retry.RetryAfterDelay(retry.FixedDelay)),
+		retry.WithTimer(recordingTimer{&delays}),
+	))
+
+	resp, err := client.Get(ts.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []time.Duration{7 * time.Second}, delays)
+}
+
+func TestTransportTransportError(t *testing.T) {
+	var calls int32
+	base := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, errors.New("connection reset")
+	})
+
+	transport := retryhttp.NewTransport(base, retry.New(retry.Attempts(3), retry.Delay(time.Millisecond)))
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	assert.NoError(t, err)
+
+	resp, err := transport.RoundTrip(req)
+	assert.Nil(t, resp)
+	var retryErr retry.Error
+	assert.ErrorAs(t, err, &retryErr)
+	assert.Len(t, retryErr, 3)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestStatusErrorRetryAfter(t *testing.T) {
+	delay := retry.RetryAfterDelay(retry.FixedDelay)
+	config := retry.New(retry.Delay(time.Second))
+
+	tests := []struct {
+		header   string
+		expected time.Duration
+	}{
+		{"", time.Second},
+		{"3", 3 * time.Second},
+		{"-1", time.Second},
+		{"garbage", time.Second},
+		{time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), time.Hour},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			var got time.Duration
+			base := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				header := http.Header{}
+				if tt.header != "" {
+					header.Set("Retry-After", tt.header)
+				}
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Status:     "503 Service Unavailable",
+					Header:     header,
+					Body:       io.NopCloser(strings.NewReader("")),
+				}, nil
+			})
+
+			retrier := retry.New(
+				retry.Attempts(1),
+				retry.RetryIf(func(err error) bool {
+					got = delay(1, err, config)
+					return false
+				}),
+			)
+			req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+			resp, err := retryhttp.NewTransport(base, retrier).RoundTrip(req)
+			assert.NoError(t, err)
+			resp.Body.Close()
+
+			assert.InDelta(t, tt.expected, got, float64(1500*time.Millisecond))
+		})
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+type recordingTimer struct {
+	delays *[]time.Duration
+}
+
+func (t recordingTimer) After(d time.Duration) <-chan time.Time {
+	*t.delays = append(*t.delays, d)
+	return time.After(0)
+}
+
+func TestTransportContextCancelled(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	client := newClient(retry.New(retry.Attempts(3), retry.Delay(time.Hour), retry.DelayType(retry.FixedDelay)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+	assert.NoError(t, err)
+
+	resp, err := client.Do(req)
+	assert.Nil(t, resp)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
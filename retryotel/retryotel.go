@@ -0,0 +1,125 @@
+/*
+Package retryotel implements retry.Tracer using the OpenTelemetry tracing API.
+
+	err := retry.New(
+		retry.Attempts(5),
+		retryotel.WithTracing(otel.GetTracerProvider()),
+	).DoCtx(
+		func(ctx context.Context) error {
+			// spans started from ctx are children of the retry span
+			...
+		},
+	)
+
+Every retry sequence is recorded as a span with an event for each finished attempt.
+The span status is set to error when the retry gives up.
+*/
+package retryotel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/avast/retry-go/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope name of the tracer
+const ScopeName = "github.com/avast/retry-go/v5/retryotel"
+
+// Tracer is a retry.Tracer starting OpenTelemetry spans
+type Tracer struct {
+	tracer   trace.Tracer
+	spanName string
+	attrs    []attribute.KeyValue
+}
+
+var _ retry.Tracer = (*Tracer)(nil)
+
+// Option represents an option for Tracer.
+type Option func(*Tracer)
+
+// NewTracer creates a new Tracer using `tp` (the global TracerProvider if nil)
+func NewTracer(tp trace.TracerProvider, opts ...Option) *Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	t := &Tracer{
+		tracer:   tp.Tracer(ScopeName),
+		spanName: retry.SpanName,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// WithTracing is a retry.Option setting a new Tracer using `tp`
+func WithTracing(tp trace.TracerProvider, opts ...Option) retry.Option {
+	return retry.WithTracer(NewTracer(tp, opts...))
+}
+
+// SpanName sets the name of the span started for every retry sequence
+// default is retry.SpanName
+func SpanName(name string) Option {
+	return func(t *Tracer) {
+		t.spanName = name
+	}
+}
+
+// Attributes sets attributes added to every span, e.g. the name of the retried operation
+func Attributes(attrs ...attribute.KeyValue) Option {
+	return func(t *Tracer) {
+		t.attrs = append(t.attrs, attrs...)
+	}
+}
+
+// Start implements retry.Tracer
+func (t *Tracer) Start(ctx context.Context, _ string) (context.Context, retry.Span) {
+	ctx, span := t.tracer.Start(ctx, t.spanName, trace.WithAttributes(t.attrs...))
+	return ctx, otelSpan{span}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+// AddEvent implements retry.Span
+func (s otelSpan) AddEvent(name string, attrs ...retry.Attribute) {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		kvs = append(kvs, keyValue(attr))
+	}
+	s.span.AddEvent(name, trace.WithAttributes(kvs...))
+}
+
+// End implements retry.Span
+func (s otelSpan) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+func keyValue(attr retry.Attribute) attribute.KeyValue {
+	switch v := attr.Value.(type) {
+	case string:
+		return attribute.String(attr.Key, v)
+	case bool:
+		return attribute.Bool(attr.Key, v)
+	case int64:
+		return attribute.Int64(attr.Key, v)
+	case time.Duration:
+		return attribute.String(attr.Key, v.String())
+	default:
+		return attribute.String(attr.Key, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,77 @@
+package retryotel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avast/retry-go/v5"
+	"github.com/avast/retry-go/v5/retryotel"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	var attemptSpan trace.SpanContext
+	err := retry.New(
+		retry.Attempts(2),
+		retry.Delay(time.Millisecond),
+		retry.DelayType(retry.FixedDelay),
+		retryotel.WithTracing(tp,
+			retryotel.SpanName("fetch"),
+			retryotel.Attributes(attribute.String("operation", "fetch")),
+		),
+	).DoCtx(
+		func(ctx context.Context) error {
+			attemptSpan = trace.SpanContextFromContext(ctx)
+			return errors.New("test")
+		},
+	)
+	assert.Error(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+
+	span := spans[0]
+	assert.Equal(t, "fetch", span.Name())
+	assert.Equal(t, span.SpanContext().SpanID(), attemptSpan.SpanID(), "attempts run in the span context")
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Contains(t, span.Attributes(), attribute.String("operation", "fetch"))
+
+	var attemptEvents []sdktrace.Event
+	for _, event := range span.Events() {
+		if event.Name == retry.AttemptEventName {
+			attemptEvents = append(attemptEvents, event)
+		}
+	}
+	assert.Len(t, attemptEvents, 2)
+	assert.Contains(t, attemptEvents[0].Attributes, attribute.Int64(retry.AttributeAttempt, 1))
+	assert.Contains(t, attemptEvents[0].Attributes, attribute.String(retry.AttributeError, "test"))
+	assert.Contains(t, attemptEvents[0].Attributes, attribute.String(retry.AttributeDelay, "1ms"))
+	assert.Contains(t, attemptEvents[1].Attributes, attribute.String(retry.AttributeStopReason, "attempts_exhausted"))
+}
+
+func TestSuccessSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	err := retry.New(
+		retryotel.WithTracing(tp),
+	).Do(
+		func() error { return nil },
+	)
+	assert.NoError(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, retry.SpanName, spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+}
@@ -0,0 +1,174 @@
+/*
+Package retryprometheus implements retry.Metrics using Prometheus client_golang.
+
+	collector := retryprometheus.NewCollector()
+	prometheus.MustRegister(collector)
+
+	retrier := retry.New(
+		retry.Attempts(5),
+		collector.WithMetrics("payments"),
+	)
+
+All metrics are labelled by the name of the retrier, give-ups are additionally labelled
+by the stop reason (see retry.StopReason.String).
+*/
+package retryprometheus
+
+import (
+	"time"
+
+	"github.com/avast/retry-go/v5"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector is a prometheus.Collector holding metrics of all named retriers
+type Collector struct {
+	namespace string
+	subsystem string
+	buckets   []float64
+
+	attempts  *prometheus.CounterVec
+	retries   *prometheus.CounterVec
+	successes *prometheus.CounterVec
+	giveUps   *prometheus.CounterVec
+	delays    *prometheus.HistogramVec
+}
+
+var _ prometheus.Collector = (*Collector)(nil)
+
+// Option represents an option for Collector.
+type Option func(*Collector)
+
+// NewCollector creates a new Collector, it has to be registered to a prometheus.Registerer
+func NewCollector(opts ...Option) *Collector {
+	c := &Collector{
+		subsystem: "retry",
+		buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.attempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: c.namespace,
+		Subsystem: c.subsystem,
+		Name:      "attempts_total",
+		Help:      "Number of attempts made by the retrier.",
+	}, []string{"name"})
+	c.retries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: c.namespace,
+		Subsystem: c.subsystem,
+		Name:      "retries_total",
+		Help:      "Number of retries (attempts after the first one) made by the retrier.",
+	}, []string{"name"})
+	c.successes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: c.namespace,
+		Subsystem: c.subsystem,
+		Name:      "successes_total",
+		Help:      "Number of retry operations which succeeded.",
+	}, []string{"name"})
+	c.giveUps = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: c.namespace,
+		Subsystem: c.subsystem,
+		Name:      "give_ups_total",
+		Help:      "Number of retry operations which gave up, by stop reason.",
+	}, []string{"name", "reason"})
+	c.delays = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: c.namespace,
+		Subsystem: c.subsystem,
+		Name:      "delay_seconds",
+		Help:      "Delays between attempts chosen by the retrier.",
+		Buckets:   c.buckets,
+	}, []string{"name"})
+
+	return c
+}
+
+// Namespace sets the namespace of all metrics
+// default is no namespace
+func Namespace(namespace string) Option {
+	return func(c *Collector) {
+		c.namespace = namespace
+	}
+}
+
+// Subsystem sets the subsystem of all metrics
+// default is "retry"
+func Subsystem(subsystem string) Option {
+	return func(c *Collector) {
+		c.subsystem = subsystem
+	}
+}
+
+// DelayBuckets sets the buckets (in seconds) of the delay histogram
+// default is prometheus.ExponentialBuckets(0.01, 2, 12)
+func DelayBuckets(buckets []float64) Option {
+	return func(c *Collector) {
+		c.buckets = buckets
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.attempts.Describe(ch)
+	c.retries.Describe(ch)
+	c.successes.Describe(ch)
+	c.giveUps.Describe(ch)
+	c.delays.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.attempts.Collect(ch)
+	c.retries.Collect(ch)
+	c.successes.Collect(ch)
+	c.giveUps.Collect(ch)
+	c.delays.Collect(ch)
+}
+
+// Metrics returns retry.Metrics of the retrier named `name`
+func (c *Collector) Metrics(name string) retry.Metrics {
+	return &metrics{
+		name:      name,
+		collector: c,
+		attempts:  c.attempts.WithLabelValues(name),
+		retries:   c.retries.WithLabelValues(name),
+		successes: c.successes.WithLabelValues(name),
+		delays:    c.delays.WithLabelValues(name),
+	}
+}
+
+// WithMetrics is a retry.Option collecting metrics of the retrier named `name`
+func (c *Collector) WithMetrics(name string) retry.Option {
+	return retry.WithMetrics(c.Metrics(name))
+}
+
+type metrics struct {
+	name      string
+	collector *Collector
+	attempts  prometheus.Counter
+	retries   prometheus.Counter
+	successes prometheus.Counter
+	delays    prometheus.Observer
+}
+
+func (m *metrics) IncAttempts() {
+	m.attempts.Inc()
+}
+
+func (m *metrics) IncRetries() {
+	m.retries.Inc()
+}
+
+func (m *metrics) IncSuccesses() {
+	m.successes.Inc()
+}
+
+func (m *metrics) IncGiveUps(reason retry.StopReason) {
+	m.collector.giveUps.WithLabelValues(m.name, reason.String()).Inc()
+}
+
+func (m *metrics) ObserveDelay(delay time.Duration) {
+	m.delays.Observe(delay.Seconds())
+}
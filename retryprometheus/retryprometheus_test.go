@@ -0,0 +1,63 @@
+package retryprometheus_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avast/retry-go/v5"
+	"github.com/avast/retry-go/v5/retryprometheus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	collector := retryprometheus.NewCollector(retryprometheus.Namespace("test"))
+	registry := prometheus.NewPedanticRegistry()
+	assert.NoError(t, registry.Register(collector))
+
+	retrier := retry.New(
+		retry.Attempts(3),
+		retry.Delay(time.Millisecond),
+		retry.DelayType(retry.FixedDelay),
+		collector.WithMetrics("payments"),
+	)
+
+	err := retrier.Do(func() error { return errors.New("test") })
+	assert.Error(t, err)
+
+	err = retrier.Do(func() error { return retry.Unrecoverable(errors.New("test")) })
+	assert.Error(t, err)
+
+	err = retry.New(collector.WithMetrics("users")).Do(func() error { return nil })
+	assert.NoError(t, err)
+
+	expected := `
+# HELP test_retry_attempts_total Number of attempts made by the retrier.
+# TYPE test_retry_attempts_total counter
+test_retry_attempts_total{name="payments"} 4
+test_retry_attempts_total{name="users"} 1
+# HELP test_retry_give_ups_total Number of retry operations which gave up, by stop reason.
+# TYPE test_retry_give_ups_total counter
+test_retry_give_ups_total{name="payments",reason="attempts_exhausted"} 1
+test_retry_give_ups_total{name="payments",reason="unrecoverable"} 1
+# HELP test_retry_retries_total Number of retries (attempts after the first one) made by the retrier.
+# TYPE test_retry_retries_total counter
+test_retry_retries_total{name="payments"} 2
+test_retry_retries_total{name="users"} 0
+# HELP test_retry_successes_total Number of retry operations which succeeded.
+# TYPE test_retry_successes_total counter
+test_retry_successes_total{name="payments"} 0
+test_retry_successes_total{name="users"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"test_retry_attempts_total",
+		"test_retry_give_ups_total",
+		"test_retry_retries_total",
+		"test_retry_successes_total",
+	))
+
+	assert.Equal(t, 2, testutil.CollectAndCount(collector, "test_retry_delay_seconds"))
+}
@@ -0,0 +1,160 @@
+/*
+Package retryslog logs retry operations using log/slog.
+
+	logger := slog.Default()
+
+	err := retry.New(
+		retry.Attempts(5),
+		retryslog.WithLogger(logger, retryslog.Operation("fetch-user")),
+	).Do(
+		func() error { ... },
+	)
+
+Each failed attempt, each wait before the next attempt and the final give-up are logged
+as structured records with the operation name, attempt number, error, delay and stop reason.
+*/
+package retryslog
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/avast/retry-go/v5"
+)
+
+// Observer is a retry.Observer writing records to a slog.Logger
+type Observer struct {
+	logger       *slog.Logger
+	operation    string
+	attemptLevel slog.Level
+	waitLevel    slog.Level
+	giveUpLevel  slog.Level
+	successLevel slog.Level
+}
+
+var _ retry.Observer = (*Observer)(nil)
+
+// Option represents an option for Observer.
+type Option func(*Observer)
+
+// New creates a new Observer logging to `logger` (slog.Default() if nil)
+func New(logger *slog.Logger, opts ...Option) *Observer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	o := &Observer{
+		logger:       logger,
+		attemptLevel: slog.LevelWarn,
+		waitLevel:    slog.LevelDebug,
+		giveUpLevel:  slog.LevelError,
+		successLevel: slog.LevelInfo,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithLogger is a retry.Option registering a new Observer logging to `logger`
+func WithLogger(logger *slog.Logger, opts ...Option) retry.Option {
+	return retry.WithObserver(New(logger, opts...))
+}
+
+// Operation sets the name of the retried operation added to every record as the "operation" attribute
+// default is no attribute
+func Operation(name string) Option {
+	return func(o *Observer) {
+		o.operation = name
+	}
+}
+
+// AttemptLevel sets the level of records about failed attempts
+// default is slog.LevelWarn
+func AttemptLevel(level slog.Level) Option {
+	return func(o *Observer) {
+		o.attemptLevel = level
+	}
+}
+
+// WaitLevel sets the level of records about waiting before the next attempt
+// default is slog.LevelDebug
+func WaitLevel(level slog.Level) Option {
+	return func(o *Observer) {
+		o.waitLevel = level
+	}
+}
+
+// GiveUpLevel sets the level of the record about giving up
+// default is slog.LevelError
+func GiveUpLevel(level slog.Level) Option {
+	return func(o *Observer) {
+		o.giveUpLevel = level
+	}
+}
+
+// SuccessLevel sets the level of the record about success after at least one failed attempt
+// default is slog.LevelInfo
+func SuccessLevel(level slog.Level) Option {
+	return func(o *Observer) {
+		o.successLevel = level
+	}
+}
+
+// OnAttemptStart implements retry.Observer
+func (o *Observer) OnAttemptStart(uint) {}
+
+// OnAttemptEnd implements retry.Observer, it logs failed attempts
+func (o *Observer) OnAttemptEnd(info retry.AttemptInfo) {
+	if info.Err == nil {
+		return
+	}
+
+	o.log(o.attemptLevel, "retry attempt failed",
+		slog.Uint64("attempt", uint64(info.Attempt)),
+		slog.Duration("duration", info.Duration),
+		slog.Any("error", info.Err),
+	)
+}
+
+// OnWait implements retry.Observer
+func (o *Observer) OnWait(attempt uint, delay time.Duration) {
+	o.log(o.waitLevel, "retry waiting",
+		slog.Uint64("attempt", uint64(attempt)),
+		slog.Duration("delay", delay),
+	)
+}
+
+// OnGiveUp implements retry.Observer
+func (o *Observer) OnGiveUp(reason retry.StopReason, err error) {
+	o.log(o.giveUpLevel, "retry gave up",
+		slog.String("reason", reason.String()),
+		slog.Any("error", err),
+	)
+}
+
+// OnSuccess implements retry.Observer, it logs only success of a retried operation
+func (o *Observer) OnSuccess(attempt uint) {
+	if attempt <= 1 {
+		return
+	}
+
+	o.log(o.successLevel, "retry succeeded",
+		slog.Uint64("attempt", uint64(attempt)),
+	)
+}
+
+func (o *Observer) log(level slog.Level, msg string, attrs ...slog.Attr) {
+	ctx := context.Background()
+	if !o.logger.Enabled(ctx, level) {
+		return
+	}
+
+	if o.operation != "" {
+		attrs = append(attrs, slog.String("operation", o.operation))
+	}
+	o.logger.LogAttrs(ctx, level, msg, attrs...)
+}
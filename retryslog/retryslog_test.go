@@ -0,0 +1,80 @@
+package retryslog_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avast/retry-go/v5"
+	"github.com/avast/retry-go/v5/retryslog"
+	"github.com/stretchr/testify/assert"
+)
+
+func newLogger(buf *bytes.Buffer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "duration" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	err := retry.New(
+		retry.Attempts(2),
+		retry.Delay(time.Millisecond),
+		retry.DelayType(retry.FixedDelay),
+		retryslog.WithLogger(newLogger(&buf, slog.LevelDebug), retryslog.Operation("fetch")),
+	).Do(
+		func() error { return errors.New("test") },
+	)
+	assert.Error(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, []string{
+		`level=WARN msg="retry attempt failed" attempt=1 error=test operation=fetch`,
+		`level=DEBUG msg="retry waiting" attempt=2 delay=1ms operation=fetch`,
+		`level=WARN msg="retry attempt failed" attempt=2 error=test operation=fetch`,
+		`level=ERROR msg="retry gave up" reason=attempts_exhausted error="All attempts fail:\n#1: test\n#2: test" operation=fetch`,
+	}, lines)
+}
+
+func TestLevels(t *testing.T) {
+	var buf bytes.Buffer
+	attempts := 0
+	err := retry.New(
+		retry.Delay(time.Nanosecond),
+		retryslog.WithLogger(newLogger(&buf, slog.LevelInfo),
+			retryslog.AttemptLevel(slog.LevelDebug),
+			retryslog.SuccessLevel(slog.LevelInfo),
+		),
+	).Do(
+		func() error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("test")
+			}
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "level=INFO msg=\"retry succeeded\" attempt=3\n", buf.String())
+}
+
+func TestNoSuccessRecordForFirstAttempt(t *testing.T) {
+	var buf bytes.Buffer
+	err := retry.New(
+		retryslog.WithLogger(newLogger(&buf, slog.LevelDebug)),
+	).Do(
+		func() error { return nil },
+	)
+	assert.NoError(t, err)
+	assert.Empty(t, buf.String())
+}
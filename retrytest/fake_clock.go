@@ -0,0 +1,215 @@
+/*
+Package retrytest provides helpers for testing code using retry.
+
+FakeClock implements retry.Timer so backoff schedules can be verified without real sleeping:
+
+	clock := retrytest.NewFakeClock()
+	retrier := retry.New(
+		retry.Attempts(3),
+		retry.DelayType(retry.BackOffDelay),
+		retry.WithTimer(clock),
+	)
+
+	done := make(chan error)
+	go func() {
+		done <- retrier.Do(func() error { return errors.New("test") })
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(100 * time.Millisecond)
+	clock.BlockUntil(1)
+	clock.Advance(200 * time.Millisecond)
+	<-done
+
+	// clock.Delays() == []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}
+*/
+package retrytest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/avast/retry-go/v5"
+)
+
+// FakeClock is a retry.Timer which fires only when the time is advanced manually.
+// FakeClock is safe for concurrent use.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*waiter
+	delays  []time.Duration
+	changed chan struct{}
+}
+
+type waiter struct {
+	deadline time.Time
+	ch       chan time.Time
+	fired    chan struct{}
+}
+
+var _ retry.Timer = (*FakeClock)(nil)
+
+// NewFakeClock creates a new FakeClock starting at an arbitrary fixed time
+func NewFakeClock() *FakeClock {
+	return NewFakeClockAt(time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC))
+}
+
+// NewFakeClockAt creates a new FakeClock starting at `now`
+func NewFakeClockAt(now time.Time) *FakeClock {
+	return &FakeClock{
+		now:     now,
+		changed: make(chan struct{}),
+	}
+}
+
+// After implements retry.Timer. The returned channel receives the current fake time
+// once the clock is advanced by at least `d`; non-positive durations fire immediately.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	ch, _ := c.after(d)
+	return ch
+}
+
+// AfterContext is After dropping the wait once `ctx` is done, so Pending and BlockUntil
+// don't count the waits of retriers which gave up because their context was cancelled.
+// Retriers use it instead of After automatically.
+func (c *FakeClock) AfterContext(ctx context.Context, d time.Duration) <-chan time.Time {
+	ch, w := c.after(d)
+	if w == nil || ctx.Done() == nil {
+		return ch
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.drop(w)
+		case <-w.fired:
+		}
+	}()
+
+	return ch
+}
+
+// after registers a wait of `d`, the returned waiter is nil if the wait fired immediately
+func (c *FakeClock) after(d time.Duration) (<-chan time.Time, *waiter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.delays = append(c.delays, d)
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch, nil
+	}
+
+	w := &waiter{deadline: c.now.Add(d), ch: ch, fired: make(chan struct{})}
+	c.waiters = append(c.waiters, w)
+	close(c.changed)
+	c.changed = make(chan struct{})
+
+	return ch, w
+}
+
+// drop removes the pending wait `w`
+func (c *FakeClock) drop(w *waiter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, pending := range c.waiters {
+		if pending == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// Now returns the current fake time, it can be used as the clock of CircuitBreaker or RetryBudget.
+// Retriers using the FakeClock as Timer measure MaxElapsedTime by it.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Advance moves the clock forward by `d` and fires all waits which are due
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.deadline.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- c.now
+		close(w.fired)
+	}
+	c.waiters = pending
+}
+
+// AdvanceToNext moves the clock forward to the earliest pending wait and fires it.
+// It returns the duration the clock moved by, 0 if there are no pending waits.
+func (c *FakeClock) AdvanceToNext() time.Duration {
+	c.mu.Lock()
+	if len(c.waiters) == 0 {
+		c.mu.Unlock()
+		return 0
+	}
+
+	next := c.waiters[0].deadline
+	for _, w := range c.waiters[1:] {
+		if w.deadline.Before(next) {
+			next = w.deadline
+		}
+	}
+	d := next.Sub(c.now)
+	c.mu.Unlock()
+
+	c.Advance(d)
+	return d
+}
+
+// Pending returns the remaining durations of all pending waits, shortest first
+func (c *FakeClock) Pending() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending := make([]time.Duration, 0, len(c.waiters))
+	for _, w := range c.waiters {
+		pending = append(pending, w.deadline.Sub(c.now))
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i] < pending[j] })
+
+	return pending
+}
+
+// Delays returns all durations requested by After, in order
+func (c *FakeClock) Delays() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]time.Duration(nil), c.delays...)
+}
+
+// BlockUntil blocks until at least `n` waits are pending,
+// i.e. until the retrier is waiting before the next attempt
+func (c *FakeClock) BlockUntil(n int) {
+	for {
+		c.mu.Lock()
+		if len(c.waiters) >= n {
+			c.mu.Unlock()
+			return
+		}
+		changed := c.changed
+		c.mu.Unlock()
+
+		<-changed
+	}
+}
@@ -0,0 +1,118 @@
+package retrytest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avast/retry-go/v5"
+	"github.com/avast/retry-go/v5/retrytest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClockBackOffSchedule(t *testing.T) {
+	clock := retrytest.NewFakeClock()
+	retrier := retry.New(
+		retry.Attempts(4),
+		retry.Delay(100*time.Millisecond),
+		retry.DelayType(retry.BackOffDelay),
+		retry.WithTimer(clock),
+	)
+
+	done := make(chan error)
+	go func() {
+		done <- retrier.Do(func() error { return errors.New("test") })
+	}()
+
+	for i := 0; i < 3; i++ {
+		clock.BlockUntil(1)
+		clock.AdvanceToNext()
+	}
+
+	assert.Error(t, <-done)
+	assert.Equal(t, []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+	}, clock.Delays())
+	assert.Empty(t, clock.Pending())
+}
+
+func TestFakeClockAdvance(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := retrytest.NewFakeClockAt(start)
+
+	short := clock.After(time.Second)
+	long := clock.After(time.Minute)
+	immediate := clock.After(0)
+
+	assert.Equal(t, start, <-immediate)
+	assert.Equal(t, []time.Duration{time.Second, time.Minute}, clock.Pending())
+
+	clock.Advance(500 * time.Millisecond)
+	select {
+	case <-short:
+		t.Fatal("fired too early")
+	default:
+	}
+	assert.Equal(t, []time.Duration{500 * time.Millisecond, time.Minute - 500*time.Millisecond}, clock.Pending())
+
+	clock.Advance(500 * time.Millisecond)
+	assert.Equal(t, start.Add(time.Second), <-short)
+
+	assert.Equal(t, time.Minute-time.Second, clock.AdvanceToNext())
+	assert.Equal(t, start.Add(time.Minute), <-long)
+	assert.Equal(t, start.Add(time.Minute), clock.Now())
+	assert.Equal(t, time.Duration(0), clock.AdvanceToNext())
+
+	assert.Equal(t, []time.Duration{time.Second, time.Minute, 0}, clock.Delays())
+}
+
+func TestFakeClockContextCancelled(t *testing.T) {
+	clock := retrytest.NewFakeClock()
+	ctx, cancel := context.WithCancel(context.Background())
+	retrier := retry.New(
+		retry.Attempts(3),
+		retry.Delay(time.Second),
+		retry.DelayType(retry.FixedDelay),
+		retry.WithTimer(clock),
+	)
+
+	done := make(chan error)
+	go func() {
+		done <- retrier.DoWithContext(ctx, func() error { return errors.New("test") })
+	}()
+
+	clock.BlockUntil(1)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	assert.Eventually(t, func() bool { return len(clock.Pending()) == 0 }, time.Second, time.Millisecond,
+		"the wait abandoned by the cancelled retrier is dropped")
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	fired := clock.AfterContext(ctx, time.Second)
+	clock.Advance(time.Second)
+	assert.Equal(t, clock.Now(), <-fired)
+	assert.Empty(t, clock.Pending())
+}
+
+func TestFakeClockWithCircuitBreaker(t *testing.T) {
+	clock := retrytest.NewFakeClock()
+	cb := retry.NewCircuitBreaker(
+		retry.BreakerConsecutiveFailures(1),
+		retry.BreakerCoolDown(time.Minute),
+		retry.BreakerClock(clock.Now),
+	)
+
+	err := retry.New(retry.Attempts(1), retry.WithCircuitBreaker(cb)).Do(
+		func() error { return errors.New("test") },
+	)
+	assert.Error(t, err)
+	assert.Equal(t, retry.CircuitOpen, cb.State())
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, retry.CircuitHalfOpen, cb.State())
+}
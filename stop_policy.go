@@ -0,0 +1,179 @@
+package retry
+
+import (
+	"errors"
+	"time"
+)
+
+// StopState describes a retry operation after a failed attempt, for StopPolicy
+type StopState struct {
+	// Attempt is the number of attempts made so far, starting at 1
+	Attempt uint
+	// Err is the error returned by the last attempt
+	Err error
+	// Elapsed is the time since the start of the retry operation
+	Elapsed time.Duration
+	// NextDelay is the delay before the next attempt if the retry continues
+	NextDelay time.Duration
+
+	// counts of StopAfterAttemptsForError, nil outside of a retry operation
+	counts *stopCounts
+}
+
+// StopPolicy decides whether a retry operation stops after a failed attempt.
+// It is consulted only for attempts which would be retried otherwise
+// (i.e. after Attempts, AttemptsForError, RetryIf, Unrecoverable and WithClassifier).
+//
+//	// 5 attempts or 30s, whichever comes first, but only 2 attempts for ErrQuota
+//	retry.Stop(retry.StopAny(
+//		retry.StopAfterAttempts(5),
+//		retry.StopAfterDelay(30*time.Second),
+//		retry.StopAfterAttemptsForError(2, ErrQuota),
+//	))
+type StopPolicy interface {
+	// ShouldStop returns true and the reason to report when the retry operation must stop,
+	// StopReasonNone is reported as StopReasonPolicy
+	ShouldStop(state StopState) (bool, StopReason)
+}
+
+// StopPolicyFunc adapts a function to StopPolicy
+type StopPolicyFunc func(state StopState) (bool, StopReason)
+
+// ShouldStop implements StopPolicy
+func (f StopPolicyFunc) ShouldStop(state StopState) (bool, StopReason) {
+	return f(state)
+}
+
+// StopAfterAttempts stops once `attempts` attempts were made, reporting StopReasonAttemptsExhausted
+func StopAfterAttempts(attempts uint) StopPolicy {
+	return StopPolicyFunc(func(state StopState) (bool, StopReason) {
+		return state.Attempt >= attempts, StopReasonAttemptsExhausted
+	})
+}
+
+// StopAfterDelay stops once the next delay would push the time since the start
+// of the retry operation past `delay`, reporting StopReasonMaxElapsedTime
+func StopAfterDelay(delay time.Duration) StopPolicy {
+	return StopPolicyFunc(func(state StopState) (bool, StopReason) {
+		return state.Elapsed+state.NextDelay > delay, StopReasonMaxElapsedTime
+	})
+}
+
+// StopOnError stops when the last error matches `target` (according to errors.Is),
+// reporting StopReasonPolicy
+func StopOnError(target error) StopPolicy {
+	return StopPolicyFunc(func(state StopState) (bool, StopReason) {
+		return errors.Is(state.Err, target), StopReasonPolicy
+	})
+}
+
+// StopAfterAttemptsForError stops once `attempts` attempts failed with an error matching `target`
+// (according to errors.Is), reporting StopReasonAttemptsForErrorExhausted.
+// Unlike StopAll(StopOnError(target), StopAfterAttempts(attempts)), only the attempts failed with `target` count.
+// The attempts are counted per retry operation when the policy is set by Stop, directly
+// or within StopAny and StopAll; elsewhere only the last error counts.
+func StopAfterAttemptsForError(attempts uint, target error) StopPolicy {
+	return &stopAfterAttemptsForError{attempts: attempts, target: target}
+}
+
+type stopAfterAttemptsForError struct {
+	attempts uint
+	target   error
+}
+
+// ShouldStop implements StopPolicy
+func (p *stopAfterAttemptsForError) ShouldStop(state StopState) (bool, StopReason) {
+	count, ok := state.counts.of(p)
+	if !ok && errors.Is(state.Err, p.target) {
+		count = 1
+	}
+	return count > 0 && count >= p.attempts, StopReasonAttemptsForErrorExhausted
+}
+
+// StopAny stops when any of `policies` stops, reporting the reason of the first one which stopped
+func StopAny(policies ...StopPolicy) StopPolicy {
+	return stopAny(append([]StopPolicy(nil), policies...))
+}
+
+type stopAny []StopPolicy
+
+// ShouldStop implements StopPolicy
+func (p stopAny) ShouldStop(state StopState) (bool, StopReason) {
+	for _, policy := range p {
+		if stop, reason := policy.ShouldStop(state); stop {
+			return true, reason
+		}
+	}
+	return false, StopReasonNone
+}
+
+// StopAll stops when all of `policies` stop, reporting the reason of the last one.
+// StopAll without policies never stops.
+func StopAll(policies ...StopPolicy) StopPolicy {
+	return stopAll(append([]StopPolicy(nil), policies...))
+}
+
+type stopAll []StopPolicy
+
+// ShouldStop implements StopPolicy
+func (p stopAll) ShouldStop(state StopState) (bool, StopReason) {
+	reason := StopReasonNone
+	for _, policy := range p {
+		stop, r := policy.ShouldStop(state)
+		if !stop {
+			return false, StopReasonNone
+		}
+		reason = r
+	}
+	return len(p) > 0, reason
+}
+
+// stopCounters returns the StopAfterAttemptsForError policies within `policy`
+func stopCounters(policy StopPolicy, counters []*stopAfterAttemptsForError) []*stopAfterAttemptsForError {
+	switch p := policy.(type) {
+	case *stopAfterAttemptsForError:
+		for _, counter := range counters {
+			if counter == p {
+				return counters
+			}
+		}
+		return append(counters, p)
+	case stopAny:
+		for _, policy := range p {
+			counters = stopCounters(policy, counters)
+		}
+	case stopAll:
+		for _, policy := range p {
+			counters = stopCounters(policy, counters)
+		}
+	}
+	return counters
+}
+
+// stopCounts counts the failed attempts of a retry operation for the StopAfterAttemptsForError policies
+type stopCounts struct {
+	counters []*stopAfterAttemptsForError
+	counts   []uint
+}
+
+// add counts the failed attempt which returned `err`
+func (c *stopCounts) add(err error) {
+	for i, counter := range c.counters {
+		if errors.Is(err, counter.target) {
+			c.counts[i]++
+		}
+	}
+}
+
+// of returns the count of `counter`, false if it is not counted
+func (c *stopCounts) of(counter *stopAfterAttemptsForError) (uint, bool) {
+	if c == nil {
+		return 0, false
+	}
+	for i, cnt := range c.counters {
+		if cnt == counter {
+			return c.counts[i], true
+		}
+	}
+	return 0, false
+}
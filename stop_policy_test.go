@@ -0,0 +1,137 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopPolicies(t *testing.T) {
+	errQuota := errors.New("quota")
+	testErr := errors.New("test")
+
+	tests := []struct {
+		name     string
+		policy   StopPolicy
+		state    StopState
+		stop     bool
+		expected StopReason
+	}{
+		{"after attempts", StopAfterAttempts(3), StopState{Attempt: 3}, true, StopReasonAttemptsExhausted},
+		{"before attempts", StopAfterAttempts(3), StopState{Attempt: 2}, false, StopReasonAttemptsExhausted},
+		{"after delay", StopAfterDelay(time.Second), StopState{Elapsed: 800 * time.Millisecond, NextDelay: 300 * time.Millisecond}, true, StopReasonMaxElapsedTime},
+		{"before delay", StopAfterDelay(time.Second), StopState{Elapsed: 800 * time.Millisecond, NextDelay: 200 * time.Millisecond}, false, StopReasonMaxElapsedTime},
+		{"on error", StopOnError(errQuota), StopState{Err: Error{testErr, errQuota}}, true, StopReasonPolicy},
+		{"on other error", StopOnError(errQuota), StopState{Err: testErr}, false, StopReasonPolicy},
+		{"any", StopAny(StopAfterAttempts(5), StopOnError(errQuota)), StopState{Attempt: 1, Err: errQuota}, true, StopReasonPolicy},
+		{"any none", StopAny(StopAfterAttempts(5), StopOnError(errQuota)), StopState{Attempt: 1, Err: testErr}, false, StopReasonNone},
+		{"any empty", StopAny(), StopState{Attempt: 100}, false, StopReasonNone},
+		{"all", StopAll(StopOnError(errQuota), StopAfterAttempts(2)), StopState{Attempt: 2, Err: errQuota}, true, StopReasonAttemptsExhausted},
+		{"all partial", StopAll(StopOnError(errQuota), StopAfterAttempts(2)), StopState{Attempt: 2, Err: testErr}, false, StopReasonNone},
+		{"all empty", StopAll(), StopState{Attempt: 100}, false, StopReasonNone},
+		{"attempts for error", StopAfterAttemptsForError(1, errQuota), StopState{Attempt: 3, Err: errQuota}, true, StopReasonAttemptsForErrorExhausted},
+		{"attempts for error counts only last error", StopAfterAttemptsForError(2, errQuota), StopState{Attempt: 3, Err: errQuota}, false, StopReasonNone},
+		{"attempts for other error", StopAfterAttemptsForError(1, errQuota), StopState{Attempt: 3, Err: testErr}, false, StopReasonNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stop, reason := tt.policy.ShouldStop(tt.state)
+			assert.Equal(t, tt.stop, stop)
+			if stop {
+				assert.Equal(t, tt.expected, reason)
+			}
+		})
+	}
+}
+
+func TestStop(t *testing.T) {
+	errQuota := errors.New("quota")
+	testErr := errors.New("test")
+
+	// the example of StopPolicy: 5 attempts or 30s, whichever comes first, but only 2 attempts for ErrQuota
+	policy := StopAny(
+		StopAfterAttempts(5),
+		StopAfterDelay(30*time.Second),
+		StopAfterAttemptsForError(2, errQuota),
+	)
+
+	tests := []struct {
+		name     string
+		errs     []error
+		delay    time.Duration
+		attempts uint
+		reason   StopReason
+	}{
+		{"attempts", []error{testErr}, time.Second, 5, StopReasonAttemptsExhausted},
+		{"quota", []error{testErr, errQuota}, time.Second, 3, StopReasonAttemptsForErrorExhausted},
+		{"quota interleaved", []error{errQuota, testErr, testErr, errQuota}, time.Second, 4, StopReasonAttemptsForErrorExhausted},
+		{"quota once", []error{errQuota, testErr}, time.Second, 5, StopReasonAttemptsExhausted},
+		{"elapsed", []error{testErr}, 12 * time.Second, 3, StopReasonMaxElapsedTime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &testClock{now: time.Unix(0, 0)}
+			observer := &recordingObserver{}
+			var attempts uint
+
+			err := New(
+				UntilSucceeded(),
+				Delay(tt.delay),
+				DelayType(FixedDelay),
+				WithTimer(clock),
+				WithObserver(observer),
+				Stop(policy),
+			).Do(func() error {
+				attempts++
+				if int(attempts) <= len(tt.errs) {
+					return tt.errs[attempts-1]
+				}
+				return tt.errs[len(tt.errs)-1]
+			})
+
+			assert.Error(t, err)
+			assert.Equal(t, tt.attempts, attempts)
+			assert.Equal(t, tt.reason, observer.reason)
+		})
+	}
+}
+
+func TestStopWithAttempts(t *testing.T) {
+	var attempts uint
+	err := New(
+		Attempts(3),
+		Delay(time.Nanosecond),
+		Stop(StopAfterAttempts(10)),
+	).Do(func() error {
+		attempts++
+		return errors.New("test")
+	})
+
+	assert.Len(t, unwrapStopError(err), 3, "Attempts still applies")
+	assert.Equal(t, uint(3), attempts)
+}
+
+func TestStopPolicyDefaultReason(t *testing.T) {
+	observer := &recordingObserver{}
+	var attempts uint
+	err := New(
+		Attempts(10),
+		Delay(time.Nanosecond),
+		WithObserver(observer),
+		Stop(StopPolicyFunc(func(state StopState) (bool, StopReason) {
+			return state.Attempt == 2, StopReasonNone
+		})),
+	).Do(func() error {
+		attempts++
+		return errors.New("test")
+	})
+
+	assert.Len(t, unwrapStopError(err), 2)
+	assert.Equal(t, uint(2), attempts)
+	assert.Equal(t, StopReasonPolicy, observer.reason)
+	assert.Equal(t, "stop_policy", StopReasonPolicy.String())
+}
@@ -0,0 +1,61 @@
+package retry
+
+import "context"
+
+// Tracer starts a span for every retry sequence.
+// It is a minimal abstraction so tracing libraries (e.g. OpenTelemetry) can be plugged in
+// without adding dependencies to this package.
+type Tracer interface {
+	// Start starts a span named `name` as a child of `ctx`; the returned context is passed to the attempts
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span represents a single retry sequence in a trace
+type Span interface {
+	// AddEvent records an event (e.g. a finished attempt) in the span
+	AddEvent(name string, attrs ...Attribute)
+	// End finishes the span, `err` is the error returned by the retry or nil on success
+	End(err error)
+}
+
+// Attribute is a key-value pair attached to span events.
+// Value is one of string, bool, int64 or time.Duration.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+const (
+	// SpanName is the name of the span started for every retry sequence
+	SpanName = "retry"
+	// AttemptEventName is the name of the span event recorded for every finished attempt
+	AttemptEventName = "retry.attempt"
+)
+
+// attempt event attribute keys
+const (
+	AttributeAttempt    = "retry.attempt"
+	AttributeError      = "retry.error"
+	AttributeDelay      = "retry.delay"
+	AttributeDuration   = "retry.duration"
+	AttributeStopReason = "retry.stop_reason"
+)
+
+// addAttemptEvent records a finished attempt in the span of the retry sequence
+func (s *retryState) addAttemptEvent(info AttemptInfo) {
+	attrs := make([]Attribute, 0, 5)
+	attrs = append(attrs,
+		Attribute{AttributeAttempt, int64(info.Attempt)},
+		Attribute{AttributeDuration, info.Duration},
+	)
+	if info.Err != nil {
+		attrs = append(attrs, Attribute{AttributeError, info.Err.Error()})
+	}
+	if info.StopReason == StopReasonNone {
+		attrs = append(attrs, Attribute{AttributeDelay, info.NextDelay})
+	} else {
+		attrs = append(attrs, Attribute{AttributeStopReason, info.StopReason.String()})
+	}
+
+	s.span.AddEvent(AttemptEventName, attrs...)
+}
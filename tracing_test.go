@@ -0,0 +1,85 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testSpanKey struct{}
+
+type testSpan struct {
+	events []map[string]interface{}
+	ended  bool
+	err    error
+}
+
+func (s *testSpan) AddEvent(name string, attrs ...Attribute) {
+	event := map[string]interface{}{"name": name}
+	for _, attr := range attrs {
+		event[attr.Key] = attr.Value
+	}
+	s.events = append(s.events, event)
+}
+
+func (s *testSpan) End(err error) {
+	s.ended = true
+	s.err = err
+}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &testSpan{}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, testSpanKey{}, span), span
+}
+
+func TestWithTracer(t *testing.T) {
+	tracer := &testTracer{}
+	attempts := 0
+	err := New(
+		Delay(time.Millisecond),
+		DelayType(FixedDelay),
+		WithTracer(tracer),
+	).DoCtx(
+		func(ctx context.Context) error {
+			assert.Equal(t, tracer.spans[0], ctx.Value(testSpanKey{}), "attempt receives span context")
+			attempts++
+			if attempts == 1 {
+				return errors.New("test")
+			}
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+	assert.Len(t, tracer.spans, 1)
+
+	span := tracer.spans[0]
+	assert.True(t, span.ended)
+	assert.NoError(t, span.err)
+	assert.Len(t, span.events, 2)
+	assert.Equal(t, AttemptEventName, span.events[0]["name"])
+	assert.Equal(t, int64(1), span.events[0][AttributeAttempt])
+	assert.Equal(t, "test", span.events[0][AttributeError])
+	assert.Equal(t, time.Millisecond, span.events[0][AttributeDelay])
+	assert.Equal(t, int64(2), span.events[1][AttributeAttempt])
+	assert.Equal(t, "success", span.events[1][AttributeStopReason])
+
+	err = New(
+		Attempts(1),
+		WithTracer(tracer),
+	).Do(
+		func() error { return errors.New("test") },
+	)
+	assert.Error(t, err)
+	assert.Len(t, tracer.spans, 2)
+	assert.True(t, tracer.spans[1].ended)
+	assert.Equal(t, err, tracer.spans[1].err)
+	assert.Equal(t, "attempts_exhausted", tracer.spans[1].events[0][AttributeStopReason])
+}